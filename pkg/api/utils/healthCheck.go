@@ -8,11 +8,16 @@ import (
 )
 
 const defaultHealthEndpointPath = "/health"
+const defaultMetricsEndpointPath = "/metrics"
 
 type ReadinessConditionFunc func() bool
 
+// StatusDetailsFunc provides additional details about the state of a service that are included in the health endpoint response
+type StatusDetailsFunc func() map[string]interface{}
+
 type StatusBody struct {
-	Status string `json:"status"`
+	Status  string                 `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
 }
 
 // ToJSON converts object to JSON string
@@ -30,6 +35,21 @@ func WithReadinessConditionFunc(rc ReadinessConditionFunc) HealthHandlerOption {
 	}
 }
 
+// WithStatusDetailsFunc allows to specify a function whose result is added as "details" to the body of an HTTP 200 (OK) response
+func WithStatusDetailsFunc(sd StatusDetailsFunc) HealthHandlerOption {
+	return func(h *healthHandler) {
+		h.statusDetailsFunc = sd
+	}
+}
+
+// WithMetricsHandler allows to specify a handler, e.g. a Prometheus handler, that serves metrics under the path '/metrics'
+// on the same port as the health endpoint
+func WithMetricsHandler(handler http.Handler) HealthHandlerOption {
+	return func(h *healthHandler) {
+		h.metricsHandler = handler
+	}
+}
+
 // WithPath allows to specify the path under which the endpoint should be reachable
 func WithPath(path string) HealthHandlerOption {
 	return func(h *healthHandler) {
@@ -39,6 +59,8 @@ func WithPath(path string) HealthHandlerOption {
 
 type healthHandler struct {
 	readinessConditionFunc ReadinessConditionFunc
+	statusDetailsFunc      StatusDetailsFunc
+	metricsHandler         http.Handler
 	path                   string
 }
 
@@ -63,6 +85,9 @@ func (h *healthHandler) healthCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	status := StatusBody{Status: "OK"}
+	if h.statusDetailsFunc != nil {
+		status.Details = h.statusDetailsFunc()
+	}
 
 	body, err := status.ToJSON()
 	if err != nil {
@@ -82,6 +107,9 @@ func (h *healthHandler) healthCheck(w http.ResponseWriter, r *http.Request) {
 func RunHealthEndpoint(port string, opts ...HealthHandlerOption) {
 	h := newHealthHandler(opts...)
 	http.HandleFunc(h.path, h.healthCheck)
+	if h.metricsHandler != nil {
+		http.Handle(defaultMetricsEndpointPath, h.metricsHandler)
+	}
 	err := http.ListenAndServe(fmt.Sprintf(":%s", port), nil)
 	if err != nil {
 		log.Println(err)
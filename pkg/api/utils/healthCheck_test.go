@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/stretchr/testify/require"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -67,3 +68,29 @@ func TestRunHealthEndpointCustomPath(t *testing.T) {
 		return true
 	}, 2*time.Second, 50*time.Millisecond)
 }
+
+func TestHealthHandler_WithStatusDetails(t *testing.T) {
+	h := newHealthHandler(WithStatusDetailsFunc(func() map[string]interface{} {
+		return map[string]interface{}{"queued": 2}
+	}))
+
+	rec := httptest.NewRecorder()
+	h.healthCheck(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"status":"OK","details":{"queued":2}}`, rec.Body.String())
+}
+
+func TestRunHealthEndpoint_WithMetricsHandler(t *testing.T) {
+	go RunHealthEndpoint("8081", WithPath("/healthz"), WithMetricsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	require.Eventually(t, func() bool {
+		get, err := http.Get("http://localhost:8081/metrics")
+		if err != nil {
+			return false
+		}
+		return get.StatusCode == http.StatusTeapot
+	}, 2*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,88 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// traceContextPropagator propagates the W3C trace context (traceparent, tracestate) as well as the W3C baggage
+var traceContextPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// KeptnCarrier adapts the extensions of a KeptnContextExtendedCE to a propagation.TextMapCarrier,
+// so that the trace context can be transported together with the event over any transport (e.g. NATS or HTTP)
+type KeptnCarrier struct {
+	event *models.KeptnContextExtendedCE
+}
+
+var _ propagation.TextMapCarrier = KeptnCarrier{}
+
+// NewKeptnCarrier creates a new KeptnCarrier for the given event
+func NewKeptnCarrier(event *models.KeptnContextExtendedCE) KeptnCarrier {
+	return KeptnCarrier{event: event}
+}
+
+// Get returns the value of the extension with the given key
+func (c KeptnCarrier) Get(key string) string {
+	extensions := c.extensions()
+	if value, ok := extensions[key]; ok {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// Set stores the given key-value pair as extension of the event. The extensions are copied before,
+// since the map might be shared with other copies of the event, e.g. the event an outgoing event has been derived from
+func (c KeptnCarrier) Set(key string, value string) {
+	current := c.extensions()
+	extensions := make(map[string]interface{}, len(current)+1)
+	for k, v := range current {
+		extensions[k] = v
+	}
+	extensions[key] = value
+	c.event.Extensions = extensions
+}
+
+// Keys lists the keys of the extensions of the event
+func (c KeptnCarrier) Keys() []string {
+	extensions := c.extensions()
+	keys := make([]string, 0, len(extensions))
+	for key := range extensions {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (c KeptnCarrier) extensions() map[string]interface{} {
+	switch extensions := c.event.Extensions.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return extensions
+	default:
+		// extensions might have been set as a different type, e.g. map[string]string, so we convert it via json
+		converted := map[string]interface{}{}
+		bytes, err := json.Marshal(extensions)
+		if err != nil {
+			return nil
+		}
+		if err := json.Unmarshal(bytes, &converted); err != nil {
+			return nil
+		}
+		return converted
+	}
+}
+
+// ExtractTraceContext returns a copy of the given context containing the trace context found
+// in the extensions of the given event
+func ExtractTraceContext(ctx context.Context, event models.KeptnContextExtendedCE) context.Context {
+	return traceContextPropagator.Extract(ctx, NewKeptnCarrier(&event))
+}
+
+// InjectTraceContext writes the trace context of the given context into the extensions of the given event
+func InjectTraceContext(ctx context.Context, event *models.KeptnContextExtendedCE) {
+	traceContextPropagator.Inject(ctx, NewKeptnCarrier(event))
+}
@@ -0,0 +1,63 @@
+package observability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestKeptnCarrier(t *testing.T) {
+	t.Run("get from json decoded extensions", func(t *testing.T) {
+		event := models.KeptnContextExtendedCE{}
+		require.NoError(t, event.FromJSON([]byte(`{"extensions": {"traceparent": "`+testTraceParent+`"}}`)))
+		carrier := NewKeptnCarrier(&event)
+		require.Equal(t, testTraceParent, carrier.Get("traceparent"))
+		require.Equal(t, []string{"traceparent"}, carrier.Keys())
+		require.Empty(t, carrier.Get("tracestate"))
+	})
+	t.Run("set on event without extensions", func(t *testing.T) {
+		event := models.KeptnContextExtendedCE{}
+		NewKeptnCarrier(&event).Set("traceparent", testTraceParent)
+		require.Equal(t, map[string]interface{}{"traceparent": testTraceParent}, event.Extensions)
+	})
+	t.Run("set on event with differently typed extensions", func(t *testing.T) {
+		event := models.KeptnContextExtendedCE{Extensions: map[string]string{"foo": "bar"}}
+		NewKeptnCarrier(&event).Set("traceparent", testTraceParent)
+		require.Equal(t, map[string]interface{}{"foo": "bar", "traceparent": testTraceParent}, event.Extensions)
+	})
+	t.Run("set does not modify extensions shared with other events", func(t *testing.T) {
+		parent := models.KeptnContextExtendedCE{Extensions: map[string]interface{}{"foo": "bar"}}
+		event := parent
+		NewKeptnCarrier(&event).Set("traceparent", testTraceParent)
+		require.Equal(t, map[string]interface{}{"foo": "bar", "traceparent": testTraceParent}, event.Extensions)
+		require.Equal(t, map[string]interface{}{"foo": "bar"}, parent.Extensions)
+	})
+}
+
+func TestExtractAndInjectTraceContext(t *testing.T) {
+	incoming := models.KeptnContextExtendedCE{Extensions: map[string]interface{}{"traceparent": testTraceParent}}
+	ctx := ExtractTraceContext(context.Background(), incoming)
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	require.True(t, spanContext.IsValid())
+	require.True(t, spanContext.IsRemote())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+
+	outgoing := models.KeptnContextExtendedCE{}
+	InjectTraceContext(ctx, &outgoing)
+	require.Equal(t, testTraceParent, NewKeptnCarrier(&outgoing).Get("traceparent"))
+}
+
+func TestExtractTraceContext_NoTraceParent(t *testing.T) {
+	ctx := ExtractTraceContext(context.Background(), models.KeptnContextExtendedCE{})
+	require.False(t, trace.SpanContextFromContext(ctx).IsValid())
+
+	outgoing := models.KeptnContextExtendedCE{}
+	InjectTraceContext(ctx, &outgoing)
+	require.Nil(t, outgoing.Extensions)
+}
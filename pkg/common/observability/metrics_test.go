@@ -0,0 +1,26 @@
+package observability
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPrometheusMeterProvider(t *testing.T) {
+	meterProvider, handler, err := NewPrometheusMeterProvider("my-service")
+	require.NoError(t, err)
+
+	counter, err := meterProvider.Meter("test").Int64Counter("test.events")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 3)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "test_events_total")
+	require.Contains(t, string(body), `service_name="my-service"`)
+}
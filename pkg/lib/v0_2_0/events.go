@@ -505,6 +505,28 @@ func CreateStartedEvent(source string, parentEvent models.KeptnContextExtendedCE
 	return createEvent(source, startedEventType, parentEvent, eventData), nil
 }
 
+// CreateStatusChangedEvent takes a parent event (e.g. .triggered event) and creates a corresponding .status.changed event
+func CreateStatusChangedEvent(source string, parentEvent models.KeptnContextExtendedCE, eventData interface{}) (*models.KeptnContextExtendedCE, error) {
+	if err := validateParentEvent(parentEvent); err != nil {
+		return nil, err
+	}
+
+	statusChangedEventType, err := ReplaceEventTypeKind(*parentEvent.Type, "status.changed")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create '.status.changed' event for parent event %s: %w", parentEvent.ID, err)
+	}
+
+	if eventData == nil {
+		commonEventData := EventData{}
+		if err := parentEvent.DataAs(&commonEventData); err != nil {
+			logrus.Errorf("unable to retrieve event data from parent event %s: %s", parentEvent.ID, err.Error())
+		}
+		eventData = commonEventData
+	}
+
+	return createEvent(source, statusChangedEventType, parentEvent, eventData), nil
+}
+
 // CreateFinishedEvent takes a parent event (e.g. .triggered event) and creates a corresponding .finished event
 func CreateFinishedEvent(source string, parentEvent models.KeptnContextExtendedCE, eventData interface{}) (*models.KeptnContextExtendedCE, error) {
 	if err := validateParentEvent(parentEvent); err != nil {
@@ -862,6 +862,90 @@ func Test_CreateStartedEvent(t *testing.T) {
 	}
 }
 
+func Test_CreateStatusChangedEvent(t *testing.T) {
+	type args struct {
+		source      string
+		parentEvent models.KeptnContextExtendedCE
+		eventData   interface{}
+	}
+	tests := []struct {
+		name        string
+		args        args
+		assertEvent func(*models.KeptnContextExtendedCE) bool
+		wantErr     bool
+	}{
+		{
+			name: "missing keptn context",
+			args: args{
+				source:      "source",
+				parentEvent: models.KeptnContextExtendedCE{},
+				eventData:   nil,
+			},
+			assertEvent: func(ce *models.KeptnContextExtendedCE) bool { return ce == nil },
+			wantErr:     true,
+		},
+		{
+			name: "non-replacable event type",
+			args: args{
+				source: "source",
+				parentEvent: models.KeptnContextExtendedCE{
+					Shkeptncontext: "abce",
+					Type:           strutils.Stringp("somethin.weird"),
+				},
+				eventData: nil,
+			},
+			assertEvent: func(ce *models.KeptnContextExtendedCE) bool { return ce == nil },
+			wantErr:     true,
+		},
+		{
+			name: "passed event data",
+			args: args{
+				source: "source",
+				parentEvent: models.KeptnContextExtendedCE{
+					ID:             "triggered-id",
+					Data:           EventData{},
+					Shkeptncontext: "abcde",
+					Type:           strutils.Stringp("sh.keptn.event.deployment.triggered"),
+				},
+				eventData: DeploymentStatusChangedEventData{EventData: EventData{Message: "50% done"}},
+			},
+			assertEvent: func(ce *models.KeptnContextExtendedCE) bool {
+				return *ce.Type == "sh.keptn.event.deployment.status.changed" &&
+					ce.Triggeredid == "triggered-id" &&
+					reflect.DeepEqual(ce.Data, DeploymentStatusChangedEventData{EventData: EventData{Message: "50% done"}})
+			},
+			wantErr: false,
+		},
+		{
+			name: "ok",
+			args: args{
+				source: "source",
+				parentEvent: models.KeptnContextExtendedCE{
+					Shkeptncontext: "abcde",
+					Type:           strutils.Stringp("sh.keptn.event.deployment.triggered"),
+					Data:           EventData{Project: "proj"},
+				},
+				eventData: nil,
+			},
+			assertEvent: func(ce *models.KeptnContextExtendedCE) bool {
+				return *ce.Type == "sh.keptn.event.deployment.status.changed" &&
+					reflect.DeepEqual(ce.Data, EventData{Project: "proj"})
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CreateStatusChangedEvent(tt.args.source, tt.args.parentEvent, tt.args.eventData)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateStatusChangedEvent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			require.True(t, tt.assertEvent(got))
+		})
+	}
+}
+
 func Test_CreateErrorLogEvent(t *testing.T) {
 	type args struct {
 		source      string
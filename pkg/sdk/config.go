@@ -0,0 +1,70 @@
+package sdk
+
+import (
+	"net/http"
+
+	"github.com/kelseyhightower/envconfig"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+	"github.com/keptn/go-utils/pkg/sdk/connector/eventsource"
+	"github.com/keptn/go-utils/pkg/sdk/connector/logforwarder"
+	"github.com/keptn/go-utils/pkg/sdk/connector/subscriptionsource"
+	sdk "github.com/keptn/go-utils/pkg/sdk/internal/api"
+	"github.com/keptn/go-utils/pkg/sdk/internal/config"
+)
+
+// Config is the configuration of the sdk. NewKeptn reads it from environment variables,
+// NewKeptnWithConfig accepts it programmatically. Use DefaultConfig as a starting point
+// to get the same defaults as for unset environment variables
+type Config = config.EnvConfig
+
+// DefaultConfig returns the configuration used by NewKeptn if no environment variables are set
+func DefaultConfig() Config {
+	return config.Defaults()
+}
+
+// ConfigFromEnv reads the configuration from environment variables
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	return cfg, err
+}
+
+// WithHTTPClient configures the sdk to use the given HTTP client to communicate with the Keptn API,
+// instead of creating one based on the configuration
+func WithHTTPClient(client *http.Client) KeptnOption {
+	return func(k *Keptn) {
+		k.httpClient = client
+	}
+}
+
+// WithAPISet configures the sdk to use the given API set instead of creating one based on the configuration
+func WithAPISet(apiSet api.KeptnInterface) KeptnOption {
+	return func(k *Keptn) {
+		k.initializationOpts = append(k.initializationOpts, sdk.WithKeptnAPI(apiSet))
+	}
+}
+
+// WithAPISetV2 configures the sdk to use the given v2 API set instead of creating one based on the configuration
+func WithAPISetV2(apiSet apiv2.KeptnInterface) KeptnOption {
+	return func(k *Keptn) {
+		k.initializationOpts = append(k.initializationOpts, sdk.WithKeptnAPIV2(apiSet))
+	}
+}
+
+// WithControlPlaneComponents configures the sdk to connect to the Keptn control plane using the given components,
+// instead of creating them based on the configuration. The log forwarder is optional
+func WithControlPlaneComponents(subscriptionSource subscriptionsource.SubscriptionSource, eventSource eventsource.EventSource, logForwarder logforwarder.LogForwarder) KeptnOption {
+	return func(k *Keptn) {
+		k.initializationOpts = append(k.initializationOpts, sdk.WithControlPlaneComponents(subscriptionSource, eventSource, logForwarder))
+	}
+}
+
+// staticHTTPClientGetter always returns the same HTTP client
+type staticHTTPClientGetter struct {
+	client *http.Client
+}
+
+func (s staticHTTPClientGetter) Get() (*http.Client, error) {
+	return s.client, nil
+}
@@ -0,0 +1,91 @@
+package sdk
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/keptn/go-utils/pkg/sdk/connector/fake"
+	"github.com/keptn/go-utils/pkg/sdk/connector/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewKeptnWithConfig(t *testing.T) {
+	t.Run("invalid configuration returns error", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.KeptnAPIEndpoint = "ssh://mynotsogoodendpoint"
+		keptnSDK, err := NewKeptnWithConfig("my-service", cfg)
+		require.Error(t, err)
+		require.Nil(t, keptnSDK)
+	})
+	t.Run("configuration is applied", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.KeptnAPIEndpoint = "http://my-keptn"
+		cfg.PubSubTopic = "sh.keptn.event.task.triggered"
+		keptnSDK, err := NewKeptnWithConfig("my-service", cfg, WithHTTPClient(&http.Client{}))
+		require.NoError(t, err)
+		require.IsType(t, &keptnapi.APISet{}, keptnSDK.APIV1())
+		require.Equal(t, "8080", keptnSDK.env.HealthEndpointPort)
+		require.Equal(t, []models.EventSubscription{{Event: "sh.keptn.event.task.triggered"}}, keptnSDK.RegistrationData().Subscriptions)
+	})
+	t.Run("injected components are used", func(t *testing.T) {
+		sentEvents := 0
+		eventSource := &fake.EventSourceMock{SenderFn: func() types.EventSender {
+			return func(ce models.KeptnContextExtendedCE) error {
+				sentEvents++
+				return nil
+			}
+		}}
+		apiSet, err := keptnapi.New("http://my-keptn")
+		require.NoError(t, err)
+
+		keptnSDK, err := NewKeptnWithConfig("my-service", DefaultConfig(),
+			WithAPISet(apiSet),
+			WithControlPlaneComponents(&fake.SubscriptionSourceMock{}, eventSource, nil),
+		)
+		require.NoError(t, err)
+		require.Same(t, apiSet, keptnSDK.APIV1())
+		require.NoError(t, keptnSDK.eventSender(models.KeptnContextExtendedCE{}))
+		require.Equal(t, 1, sentEvents)
+	})
+	t.Run("missing components are created", func(t *testing.T) {
+		sentEvents := 0
+		eventSource := &fake.EventSourceMock{SenderFn: func() types.EventSender {
+			return func(ce models.KeptnContextExtendedCE) error {
+				sentEvents++
+				return nil
+			}
+		}}
+
+		keptnSDK, err := NewKeptnWithConfig("my-service", DefaultConfig(), WithControlPlaneComponents(nil, eventSource, nil))
+		require.NoError(t, err)
+		require.NoError(t, keptnSDK.eventSender(models.KeptnContextExtendedCE{}))
+		require.Equal(t, 1, sentEvents)
+	})
+}
+
+func TestDefaultConfig(t *testing.T) {
+	// unset all variables, so that envconfig falls back to the defaults
+	fields := reflect.TypeOf(Config{})
+	for i := 0; i < fields.NumField(); i++ {
+		key := fields.Field(i).Tag.Get("envconfig")
+		t.Setenv(key, "")
+		require.NoError(t, os.Unsetenv(key))
+	}
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, cfg, DefaultConfig())
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("PUBSUB_TOPIC", "sh.keptn.event.task.triggered")
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	expected := DefaultConfig()
+	expected.PubSubTopic = "sh.keptn.event.task.triggered"
+	expected.OAuthScopes = cfg.OAuthScopes
+	require.Equal(t, expected, cfg)
+}
@@ -9,10 +9,10 @@ import (
 	"github.com/keptn/go-utils/pkg/sdk/connector/eventsource"
 	"github.com/keptn/go-utils/pkg/sdk/connector/logforwarder"
 	"github.com/keptn/go-utils/pkg/sdk/connector/logger"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
 	"github.com/keptn/go-utils/pkg/sdk/connector/subscriptionsource"
 	"github.com/keptn/go-utils/pkg/sdk/connector/types"
 	"log"
-	"os"
 	"os/signal"
 	"sync"
 	"syscall"
@@ -27,6 +27,9 @@ const tmpDataDistributorKey = "distributor"
 
 var ErrEventHandleFatal = errors.New("fatal event handling error")
 
+// ErrGracefulShutdownTimeout is returned by RunWithGracefulShutdown if the control plane did not stop within the shutdown timeout
+var ErrGracefulShutdownTimeout = errors.New("failed to gracefully shutdown")
+
 // Integration represents a Keptn Service that wants to receive events from the Keptn Control plane
 type Integration interface {
 	// OnEvent is called when a new event was received
@@ -45,6 +48,7 @@ type ControlPlane struct {
 	registered            bool
 	integrationID         string
 	logForwarder          logforwarder.LogForwarder
+	metrics               *metrics.Metrics
 	mtx                   *sync.RWMutex
 	eventHandlerWaitGroup *sync.WaitGroup
 }
@@ -56,28 +60,44 @@ func WithLogger(logger logger.Logger) func(plane *ControlPlane) {
 	}
 }
 
+// WithMetrics sets the metrics to record received and dropped events
+func WithMetrics(metrics *metrics.Metrics) func(plane *ControlPlane) {
+	return func(ns *ControlPlane) {
+		ns.metrics = metrics
+	}
+}
+
 // RunWithGracefulShutdown starts the controlplane component which takes care of registering
 // the integration and handling events and subscriptions. Further, it supports graceful shutdown handling
 // when receiving a SIGHUB, SIGINT, SIGQUIT, SIGARBT or SIGTERM signal.
+// If the controlplane has not stopped within the shutdownTimeout after receiving the signal, ErrGracefulShutdownTimeout
+// is returned, leaving it up to the caller to decide how to terminate.
 //
 // This call is blocking.
 //
 //If you want to start the controlPlane component with an own context you need to call the Register(ctx,integration)
 // method on your own
 func RunWithGracefulShutdown(controlPlane *ControlPlane, integration Integration, shutdownTimeout time.Duration) error {
-	ctxShutdown, cancel := context.WithCancel(context.Background())
+	ctxShutdown, cancel := signal.NotifyContext(context.Background(), syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGABRT, syscall.SIGTERM)
 	defer cancel()
 
-	ctxShutdown, _ = signal.NotifyContext(ctxShutdown, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGABRT, syscall.SIGTERM)
+	errC := make(chan error, 1)
 	go func() {
-		<-ctxShutdown.Done()
-		time.Sleep(shutdownTimeout) // shutdown timeout
-		log.Printf("failed to gracefully shutdown")
-		os.Exit(1)
+		errC <- controlPlane.Register(ctxShutdown, integration)
 	}()
 
-	return controlPlane.Register(ctxShutdown, integration)
+	select {
+	case err := <-errC:
+		return err
+	case <-ctxShutdown.Done():
+	}
 
+	select {
+	case err := <-errC:
+		return err
+	case <-time.After(shutdownTimeout):
+		return ErrGracefulShutdownTimeout
+	}
 }
 
 // New creates a new ControlPlane
@@ -91,6 +111,7 @@ func New(subscriptionSource subscriptionsource.SubscriptionSource, eventSource e
 		currentSubscriptions:  []models.EventSubscription{},
 		logger:                logger.NewDefaultLogger(),
 		logForwarder:          logForwarder,
+		metrics:               metrics.NewDefault(),
 		registered:            false,
 		mtx:                   &sync.RWMutex{},
 		eventHandlerWaitGroup: &sync.WaitGroup{},
@@ -199,15 +220,18 @@ func (cp *ControlPlane) stopComponents() {
 
 func (cp *ControlPlane) handle(ctx context.Context, eventUpdate types.EventUpdate, integration Integration) error {
 	cp.logger.Debugf("Received an event of type: %s", *eventUpdate.KeptnEvent.Type)
+	cp.metrics.EventReceived(ctx, metrics.ComponentControlPlane, eventUpdate.KeptnEvent)
 	// if we already know the subscription ID we can just forward the event to be handled
 	if eventUpdate.SubscriptionID != "" {
 		return cp.forwardMatchedEvent(ctx, eventUpdate, integration, eventUpdate.SubscriptionID)
 	}
+	matched := false
 	for _, subscription := range cp.currentSubscriptions {
 		if subscription.Event == eventUpdate.MetaData.Subject {
 			cp.logger.Debugf("Check if event matches subscription %s", subscription.ID)
 			matcher := eventmatcher.New(subscription)
 			if matcher.Matches(eventUpdate.KeptnEvent) {
+				matched = true
 				cp.logger.Info("Forwarding matched event update: ", eventUpdate.KeptnEvent.ID)
 				if err := cp.forwardMatchedEvent(ctx, eventUpdate, integration, subscription.ID); err != nil {
 					return err
@@ -215,6 +239,9 @@ func (cp *ControlPlane) handle(ctx context.Context, eventUpdate types.EventUpdat
 			}
 		}
 	}
+	if !matched {
+		cp.metrics.EventDropped(ctx, metrics.ComponentControlPlane, eventUpdate.KeptnEvent, metrics.ReasonNoSubscription)
+	}
 	return nil
 }
 
@@ -250,7 +277,9 @@ func (cp *ControlPlane) forwardMatchedEvent(ctx context.Context, eventUpdate typ
 	if err != nil {
 		cp.logger.Warnf("Could not append subscription data to event: %v", err)
 	}
-	if err := integration.OnEvent(context.WithValue(ctx, types.EventSenderKey, cp.getSender(cp.eventSource.Sender())), eventUpdate.KeptnEvent); err != nil {
+	ctx = context.WithValue(ctx, types.EventSenderKey, cp.getSender(cp.eventSource.Sender()))
+	ctx = context.WithValue(ctx, types.IntegrationIDKey, cp.integrationID)
+	if err := integration.OnEvent(ctx, eventUpdate.KeptnEvent); err != nil {
 		if errors.Is(err, ErrEventHandleFatal) {
 			cp.logger.Errorf("Fatal error during handling of event: %v", err)
 			return err
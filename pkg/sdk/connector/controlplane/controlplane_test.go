@@ -408,6 +408,7 @@ func TestControlPlaneIntegrationIDIsForwarded(t *testing.T) {
 	var eventChan chan types.EventUpdate
 	var subsChan chan []models.EventSubscription
 	var integrationReceivedEvent models.KeptnContextExtendedCE
+	var integrationReceivedID interface{}
 
 	mtx := sync.RWMutex{}
 	eventUpdate := types.EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: types.EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
@@ -455,6 +456,7 @@ func TestControlPlaneIntegrationIDIsForwarded(t *testing.T) {
 			mtx.Lock()
 			defer mtx.Unlock()
 			integrationReceivedEvent = ce
+			integrationReceivedID = ctx.Value(types.IntegrationIDKey)
 			return nil
 		},
 	}
@@ -491,6 +493,7 @@ func TestControlPlaneIntegrationIDIsForwarded(t *testing.T) {
 			},
 		},
 	}, eventData)
+	require.Equal(t, "some-other-id", integrationReceivedID)
 }
 
 func TestControlPlaneIntegrationOnEventThrowsIgnoreableError(t *testing.T) {
@@ -7,6 +7,7 @@ import (
 	"github.com/keptn/go-utils/pkg/api/models"
 	api "github.com/keptn/go-utils/pkg/api/utils"
 	"github.com/keptn/go-utils/pkg/sdk/connector/logger"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
 	"github.com/keptn/go-utils/pkg/sdk/connector/types"
 	"sync"
 	"time"
@@ -24,6 +25,13 @@ func WithLogger(logger logger.Logger) func(plane *HTTPEventSource) {
 	}
 }
 
+// WithMetrics sets the metrics to record received events
+func WithMetrics(metrics *metrics.Metrics) func(plane *HTTPEventSource) {
+	return func(ns *HTTPEventSource) {
+		ns.metrics = metrics
+	}
+}
+
 // WithMaxPollingAttempts sets the max number of attempts the HTTPEventSource shall retry to poll for new
 // events when failing
 func WithMaxPollingAttempts(maxPollingAttempts int) func(plane *HTTPEventSource) {
@@ -51,6 +59,7 @@ func New(clock clock.Clock, eventGetSender EventAPI, opts ...func(source *HTTPEv
 		quitC:                make(chan struct{}, 1),
 		cache:                NewCache(),
 		logger:               logger.NewDefaultLogger(),
+		metrics:              metrics.NewDefault(),
 	}
 	for _, o := range opts {
 		o(e)
@@ -68,6 +77,7 @@ type HTTPEventSource struct {
 	quitC                chan struct{}
 	cache                *cache
 	logger               logger.Logger
+	metrics              *metrics.Metrics
 }
 
 func (hes *HTTPEventSource) Start(ctx context.Context, data types.RegistrationData, updates chan types.EventUpdate, errChan chan error, wg *sync.WaitGroup) error {
@@ -137,6 +147,7 @@ func (hes *HTTPEventSource) doPoll(eventUpdates chan types.EventUpdate) error {
 			if hes.cache.contains(sub.ID, e.ID) {
 				continue
 			}
+			hes.metrics.EventReceived(context.Background(), metrics.ComponentHTTPEventSource, *e)
 			eventUpdates <- types.EventUpdate{
 				KeptnEvent:     *e,
 				MetaData:       types.EventUpdateMetaData{Subject: sub.Event},
@@ -0,0 +1,359 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/eventsource"
+	"github.com/keptn/go-utils/pkg/sdk/connector/logger"
+	"github.com/keptn/go-utils/pkg/sdk/connector/types"
+)
+
+var _ eventsource.EventSource = (*LocalEventSource)(nil)
+
+// ErrInputsConsumed is reported via the error channel passed to Start when all events of the configured directory
+// and reader have been read and WithExitWhenDone has been set, so that the control plane stops
+var ErrInputsConsumed = errors.New("all events have been read")
+
+// LocalEventSource is an implementation of EventSource that reads events from JSON files, a reader such as stdin,
+// or a local HTTP CloudEvents receiver instead of the Keptn control plane. Events sent via its sender are written
+// to an output writer, such as stdout, or to files. It can be used to run an integration locally
+type LocalEventSource struct {
+	mtx             sync.RWMutex
+	subjects        []string
+	subscribed      chan struct{}
+	subscribedOnce  sync.Once
+	directory       string
+	reader          io.Reader
+	httpAddress     string
+	httpServer      *http.Server
+	listener        net.Listener
+	output          io.Writer
+	outputDirectory string
+	outputMtx       sync.Mutex
+	exitWhenDone    bool
+	logger          logger.Logger
+	quitC           chan struct{}
+	quitOnce        sync.Once
+}
+
+// New creates a new LocalEventSource
+func New(opts ...func(source *LocalEventSource)) *LocalEventSource {
+	l := &LocalEventSource{
+		subjects:   []string{},
+		subscribed: make(chan struct{}),
+		output:     os.Stdout,
+		logger:     logger.NewDefaultLogger(),
+		quitC:      make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// WithLogger sets the logger to use
+func WithLogger(logger logger.Logger) func(*LocalEventSource) {
+	return func(l *LocalEventSource) {
+		l.logger = logger
+	}
+}
+
+// WithDirectory reads the events from the JSON files contained in the given directory, in lexical order of the file names.
+// A file can either contain a single event or a list of events
+func WithDirectory(directory string) func(*LocalEventSource) {
+	return func(l *LocalEventSource) {
+		l.directory = directory
+	}
+}
+
+// WithReader reads the events, encoded as a stream of JSON objects, from the given reader, e.g. os.Stdin
+func WithReader(reader io.Reader) func(*LocalEventSource) {
+	return func(l *LocalEventSource) {
+		l.reader = reader
+	}
+}
+
+// WithHTTPReceiver starts an HTTP server listening on the given address, which receives CloudEvents
+// in structured or binary content mode
+func WithHTTPReceiver(address string) func(*LocalEventSource) {
+	return func(l *LocalEventSource) {
+		l.httpAddress = address
+	}
+}
+
+// WithOutput writes the sent events as JSON to the given writer. Per default, os.Stdout is used
+func WithOutput(output io.Writer) func(*LocalEventSource) {
+	return func(l *LocalEventSource) {
+		l.output = output
+	}
+}
+
+// WithOutputDirectory writes every sent event as JSON file to the given directory instead of the output writer
+func WithOutputDirectory(directory string) func(*LocalEventSource) {
+	return func(l *LocalEventSource) {
+		l.outputDirectory = directory
+	}
+}
+
+// WithExitWhenDone stops the control plane as soon as all events of the configured directory and reader have been read.
+// It has no effect if an HTTP receiver is configured
+func WithExitWhenDone() func(*LocalEventSource) {
+	return func(l *LocalEventSource) {
+		l.exitWhenDone = true
+	}
+}
+
+func (l *LocalEventSource) Start(ctx context.Context, registrationData types.RegistrationData, eventChannel chan types.EventUpdate, errChan chan error, wg *sync.WaitGroup) error {
+	if l.httpAddress != "" {
+		listener, err := net.Listen("tcp", l.httpAddress)
+		if err != nil {
+			return fmt.Errorf("could not start local event source: %w", err)
+		}
+		l.listener = listener
+		l.httpServer = &http.Server{Handler: l.httpHandler(ctx, eventChannel)}
+		go func() {
+			if err := l.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				l.logger.Errorf("Local HTTP receiver stopped: %v", err)
+			}
+		}()
+		l.logger.Infof("Receiving CloudEvents on %s", listener.Addr())
+	}
+
+	go func() {
+		// wait for the subscriptions before dispatching events, as events without a matching subscription are dropped
+		select {
+		case <-l.subscribed:
+		case <-ctx.Done():
+			return
+		case <-l.quitC:
+			return
+		}
+		err := l.readInputs(ctx, eventChannel)
+		if err == nil && l.exitWhenDone && l.httpAddress == "" {
+			err = ErrInputsConsumed
+		}
+		if err == nil {
+			return
+		}
+		select {
+		case errChan <- err:
+		case <-ctx.Done():
+		case <-l.quitC:
+		}
+	}()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-l.quitC:
+		}
+		l.stopHTTPReceiver()
+		wg.Done()
+	}()
+	return nil
+}
+
+// Addr returns the address the HTTP receiver is listening on, if any
+func (l *LocalEventSource) Addr() net.Addr {
+	if l.listener == nil {
+		return nil
+	}
+	return l.listener.Addr()
+}
+
+func (l *LocalEventSource) readInputs(ctx context.Context, eventChannel chan types.EventUpdate) error {
+	if l.directory != "" {
+		if err := l.readDirectory(ctx, eventChannel); err != nil {
+			return err
+		}
+	}
+	if l.reader != nil {
+		decoder := json.NewDecoder(l.reader)
+		for {
+			event := models.KeptnContextExtendedCE{}
+			err := decoder.Decode(&event)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("could not decode event: %w", err)
+			}
+			l.dispatch(ctx, eventChannel, event)
+		}
+	}
+	return nil
+}
+
+func (l *LocalEventSource) readDirectory(ctx context.Context, eventChannel chan types.EventUpdate) error {
+	files, err := filepath.Glob(filepath.Join(l.directory, "*.json"))
+	if err != nil {
+		return fmt.Errorf("could not read events directory: %w", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("could not read event file %s: %w", file, err)
+		}
+		events := []models.KeptnContextExtendedCE{}
+		if strings.HasPrefix(strings.TrimSpace(string(content)), "[") {
+			err = json.Unmarshal(content, &events)
+		} else {
+			event := models.KeptnContextExtendedCE{}
+			err = json.Unmarshal(content, &event)
+			events = append(events, event)
+		}
+		if err != nil {
+			return fmt.Errorf("could not decode event file %s: %w", file, err)
+		}
+		for _, event := range events {
+			l.dispatch(ctx, eventChannel, event)
+		}
+	}
+	return nil
+}
+
+func (l *LocalEventSource) httpHandler(ctx context.Context, eventChannel chan types.EventUpdate) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		ce, err := cehttp.NewEventFromHTTPRequest(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("could not decode CloudEvent: %v", err), http.StatusBadRequest)
+			return
+		}
+		event, err := keptnv2.ToKeptnEvent(*ce)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("could not convert CloudEvent: %v", err), http.StatusBadRequest)
+			return
+		}
+		l.dispatch(ctx, eventChannel, event)
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// dispatch passes the given event to the control plane once for every subscribed subject matching the event type
+func (l *LocalEventSource) dispatch(ctx context.Context, eventChannel chan types.EventUpdate, event models.KeptnContextExtendedCE) {
+	if event.Type == nil {
+		l.logger.Warnf("Skipping event %s without type", event.ID)
+		return
+	}
+	l.mtx.RLock()
+	subjects := l.subjects
+	l.mtx.RUnlock()
+	matched := false
+	for _, subject := range subjects {
+		if !subjectMatches(subject, *event.Type) {
+			continue
+		}
+		matched = true
+		select {
+		case eventChannel <- types.EventUpdate{KeptnEvent: event, MetaData: types.EventUpdateMetaData{Subject: subject}}:
+		case <-ctx.Done():
+			return
+		case <-l.quitC:
+			return
+		}
+	}
+	if !matched {
+		l.logger.Infof("Skipping event %s of type %s without matching subscription", event.ID, *event.Type)
+	}
+}
+
+func (l *LocalEventSource) OnSubscriptionUpdate(subscriptions []models.EventSubscription) {
+	subjects := []string{}
+	seen := map[string]bool{}
+	for _, s := range subscriptions {
+		if !seen[s.Event] {
+			seen[s.Event] = true
+			subjects = append(subjects, s.Event)
+		}
+	}
+	l.mtx.Lock()
+	l.subjects = subjects
+	l.mtx.Unlock()
+	l.subscribedOnce.Do(func() {
+		close(l.subscribed)
+	})
+}
+
+// Sender returns an EventSender writing the events to the configured output
+func (l *LocalEventSource) Sender() types.EventSender {
+	return l.write
+}
+
+func (l *LocalEventSource) write(event models.KeptnContextExtendedCE) error {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("could not encode event: %w", err)
+	}
+	l.outputMtx.Lock()
+	defer l.outputMtx.Unlock()
+	if l.outputDirectory != "" {
+		eventType := ""
+		if event.Type != nil {
+			eventType = *event.Type
+		}
+		file := filepath.Join(l.outputDirectory, fmt.Sprintf("%s-%s.json", eventType, event.ID))
+		if err := os.WriteFile(file, content, 0600); err != nil {
+			return fmt.Errorf("could not write event: %w", err)
+		}
+		return nil
+	}
+	if _, err := fmt.Fprintln(l.output, string(content)); err != nil {
+		return fmt.Errorf("could not write event: %w", err)
+	}
+	return nil
+}
+
+func (l *LocalEventSource) Stop() error {
+	l.quitOnce.Do(func() {
+		close(l.quitC)
+	})
+	return nil
+}
+
+func (l *LocalEventSource) Cleanup() error {
+	return nil
+}
+
+func (l *LocalEventSource) stopHTTPReceiver() {
+	if l.httpServer == nil {
+		return
+	}
+	if err := l.httpServer.Close(); err != nil {
+		l.logger.Errorf("Unable to stop local HTTP receiver: %v", err)
+	}
+}
+
+// subjectMatches checks whether the given event type matches the given NATS subject, which can contain
+// the wildcards "*", matching a single token, and ">", matching one or more trailing tokens
+func subjectMatches(subject string, eventType string) bool {
+	subjectTokens := strings.Split(subject, ".")
+	typeTokens := strings.Split(eventType, ".")
+	for i, token := range subjectTokens {
+		if token == ">" {
+			return len(typeTokens) > i
+		}
+		if i >= len(typeTokens) || (token != "*" && token != typeTokens[i]) {
+			return false
+		}
+	}
+	return len(subjectTokens) == len(typeTokens)
+}
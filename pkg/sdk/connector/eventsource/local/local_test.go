@@ -0,0 +1,136 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/keptn/go-utils/pkg/sdk/connector/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEvent(id string, eventType string) models.KeptnContextExtendedCE {
+	return models.KeptnContextExtendedCE{ID: id, Type: strutils.Stringp(eventType), Shkeptncontext: "context", Source: strutils.Stringp("test")}
+}
+
+func writeJSON(t *testing.T, file string, v interface{}) {
+	content, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, content, 0600))
+}
+
+func receive(t *testing.T, eventChannel chan types.EventUpdate, n int) []string {
+	received := []string{}
+	for i := 0; i < n; i++ {
+		select {
+		case update := <-eventChannel:
+			received = append(received, update.KeptnEvent.ID+"@"+update.MetaData.Subject)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d events", i, n)
+		}
+	}
+	return received
+}
+
+func TestLocalEventSource_ReadsDirectoryAndReader(t *testing.T) {
+	dir := t.TempDir()
+	writeJSON(t, filepath.Join(dir, "01.json"), newTestEvent("1", "sh.keptn.event.deployment.triggered"))
+	writeJSON(t, filepath.Join(dir, "02.json"), []models.KeptnContextExtendedCE{
+		newTestEvent("2", "sh.keptn.event.test.triggered"),
+		newTestEvent("3", "sh.keptn.event.unknown.triggered"),
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("no event"), 0600))
+	reader := &bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(reader).Encode(newTestEvent("4", "sh.keptn.event.test.finished")))
+
+	eventSource := New(WithDirectory(dir), WithReader(reader), WithExitWhenDone())
+	eventChannel := make(chan types.EventUpdate)
+	errChan := make(chan error, 1)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, eventSource.Start(ctx, types.RegistrationData{}, eventChannel, errChan, wg))
+	eventSource.OnSubscriptionUpdate([]models.EventSubscription{
+		{Event: "sh.keptn.event.deployment.triggered"},
+		{Event: "sh.keptn.event.*.triggered"},
+		{Event: "sh.keptn.event.test.>"},
+	})
+
+	require.Equal(t, []string{
+		"1@sh.keptn.event.deployment.triggered",
+		"1@sh.keptn.event.*.triggered",
+		"2@sh.keptn.event.*.triggered",
+		"2@sh.keptn.event.test.>",
+		"3@sh.keptn.event.*.triggered",
+		"4@sh.keptn.event.test.>",
+	}, receive(t, eventChannel, 6))
+	require.ErrorIs(t, <-errChan, ErrInputsConsumed)
+
+	cancel()
+	wg.Wait()
+}
+
+func TestLocalEventSource_HTTPReceiver(t *testing.T) {
+	eventSource := New(WithHTTPReceiver("localhost:0"))
+	eventChannel := make(chan types.EventUpdate, 1)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	require.NoError(t, eventSource.Start(context.Background(), types.RegistrationData{}, eventChannel, make(chan error, 1), wg))
+	eventSource.OnSubscriptionUpdate([]models.EventSubscription{{Event: "sh.keptn.event.test.triggered"}})
+
+	body := `{"specversion":"1.0","id":"1","source":"test","type":"sh.keptn.event.test.triggered","shkeptncontext":"context","datacontenttype":"application/json","data":{"project":"prj"}}`
+	resp, err := http.Post("http://"+eventSource.Addr().String(), "application/cloudevents+json", strings.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	update := <-eventChannel
+	require.Equal(t, "1", update.KeptnEvent.ID)
+	require.Equal(t, "context", update.KeptnEvent.Shkeptncontext)
+	require.Equal(t, "sh.keptn.event.test.triggered", update.MetaData.Subject)
+
+	require.NoError(t, eventSource.Stop())
+	wg.Wait()
+}
+
+func TestLocalEventSource_Sender(t *testing.T) {
+	output := &bytes.Buffer{}
+	require.NoError(t, New(WithOutput(output)).Sender()(newTestEvent("1", "sh.keptn.event.test.started")))
+	written := models.KeptnContextExtendedCE{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &written))
+	require.Equal(t, "1", written.ID)
+
+	dir := t.TempDir()
+	require.NoError(t, New(WithOutputDirectory(dir)).Sender()(newTestEvent("2", "sh.keptn.event.test.finished")))
+	require.FileExists(t, filepath.Join(dir, "sh.keptn.event.test.finished-2.json"))
+}
+
+func Test_subjectMatches(t *testing.T) {
+	tests := []struct {
+		subject   string
+		eventType string
+		want      bool
+	}{
+		{"sh.keptn.event.test.triggered", "sh.keptn.event.test.triggered", true},
+		{"sh.keptn.event.test.triggered", "sh.keptn.event.test.finished", false},
+		{"sh.keptn.event.*.triggered", "sh.keptn.event.test.triggered", true},
+		{"sh.keptn.event.*.triggered", "sh.keptn.event.test.status.changed", false},
+		{"sh.keptn.event.test.>", "sh.keptn.event.test.status.changed", true},
+		{"sh.keptn.event.test.>", "sh.keptn.event.test", false},
+		{"sh.keptn.event.test", "sh.keptn.event.test.triggered", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject+" "+tt.eventType, func(t *testing.T) {
+			require.Equal(t, tt.want, subjectMatches(tt.subject, tt.eventType))
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/keptn/go-utils/pkg/api/models"
 	"github.com/keptn/go-utils/pkg/sdk/connector/logger"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
 	natseventsource "github.com/keptn/go-utils/pkg/sdk/connector/nats"
 	"github.com/nats-io/nats.go"
 )
@@ -23,6 +24,7 @@ type NATSEventSource struct {
 	eventProcessFn  natseventsource.ProcessEventFn
 	queueGroup      string
 	logger          logger.Logger
+	metrics         *metrics.Metrics
 	quitC           chan struct{}
 }
 
@@ -34,6 +36,7 @@ func New(natsConnector natseventsource.NATS, opts ...func(source *NATSEventSourc
 		eventProcessFn:  func(event *nats.Msg) error { return nil },
 		quitC:           make(chan struct{}, 1),
 		logger:          logger.NewDefaultLogger(),
+		metrics:         metrics.NewDefault(),
 	}
 	for _, o := range opts {
 		o(e)
@@ -48,13 +51,22 @@ func WithLogger(logger logger.Logger) func(*NATSEventSource) {
 	}
 }
 
+// WithMetrics sets the metrics to record received events
+func WithMetrics(metrics *metrics.Metrics) func(*NATSEventSource) {
+	return func(ns *NATSEventSource) {
+		ns.metrics = metrics
+	}
+}
+
 func (n *NATSEventSource) Start(ctx context.Context, registrationData types.RegistrationData, eventChannel chan types.EventUpdate, errChan chan error, wg *sync.WaitGroup) error {
 	n.queueGroup = registrationData.Name
 	n.eventProcessFn = func(event *nats.Msg) error {
 		keptnEvent := models.KeptnContextExtendedCE{}
 		if err := json.Unmarshal(event.Data, &keptnEvent); err != nil {
+			n.metrics.EventDropped(ctx, metrics.ComponentNATSEventSource, keptnEvent, metrics.ReasonInvalidEvent)
 			return fmt.Errorf("could not unmarshal message: %w", err)
 		}
+		n.metrics.EventReceived(ctx, metrics.ComponentNATSEventSource, keptnEvent)
 		eventChannel <- types.EventUpdate{
 			KeptnEvent: keptnEvent,
 			MetaData:   types.EventUpdateMetaData{event.Sub.Subject},
@@ -1,6 +1,7 @@
 package logforwarder
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -8,6 +9,7 @@ import (
 	api "github.com/keptn/go-utils/pkg/api/utils"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/go-utils/pkg/sdk/connector/logger"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
 )
 
 //go:generate moq -pkg fake -skip-ensure -out ./fake/logapi.go . logAPI:LogAPIMock
@@ -20,14 +22,16 @@ type LogForwarder interface {
 var _ LogForwarder = LogForwardingHandler{}
 
 type LogForwardingHandler struct {
-	logApi api.LogsV1Interface
-	logger logger.Logger
+	logApi  api.LogsV1Interface
+	logger  logger.Logger
+	metrics *metrics.Metrics
 }
 
 func New(logApi api.LogsV1Interface, opts ...func(handler *LogForwardingHandler)) *LogForwardingHandler {
 	l := &LogForwardingHandler{
-		logApi: logApi,
-		logger: logger.NewDefaultLogger(),
+		logApi:  logApi,
+		logger:  logger.NewDefaultLogger(),
+		metrics: metrics.NewDefault(),
 	}
 	for _, o := range opts {
 		o(l)
@@ -42,6 +46,13 @@ func WithLogger(logger logger.Logger) func(*LogForwardingHandler) {
 	}
 }
 
+// WithMetrics sets the metrics to record forwarded logs
+func WithMetrics(metrics *metrics.Metrics) func(*LogForwardingHandler) {
+	return func(lfh *LogForwardingHandler) {
+		lfh.metrics = metrics
+	}
+}
+
 func (l LogForwardingHandler) Forward(keptnEvent models.KeptnContextExtendedCE, integrationID string) error {
 	if integrationID == "" {
 		return nil
@@ -67,7 +78,7 @@ func (l LogForwardingHandler) Forward(keptnEvent models.KeptnContextExtendedCE,
 				Task:          taskName,
 				TriggeredID:   keptnEvent.Triggeredid,
 			}})
-			l.logApi.Flush()
+			l.flush(keptnEvent)
 		}
 		return nil
 	} else if *keptnEvent.Type == keptnv2.ErrorLogEventName {
@@ -89,7 +100,15 @@ func (l LogForwardingHandler) Forward(keptnEvent models.KeptnContextExtendedCE,
 			Task:          eventData.Task,
 			TriggeredID:   keptnEvent.Triggeredid,
 		}})
-		l.logApi.Flush()
+		l.flush(keptnEvent)
 	}
 	return nil
 }
+
+func (l LogForwardingHandler) flush(keptnEvent models.KeptnContextExtendedCE) {
+	if err := l.logApi.Flush(); err != nil {
+		l.metrics.LogForwarded(context.Background(), keptnEvent, metrics.ResultFailure)
+		return
+	}
+	l.metrics.LogForwarded(context.Background(), keptnEvent, metrics.ResultSuccess)
+}
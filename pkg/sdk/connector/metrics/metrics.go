@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/keptn/go-utils/pkg/sdk"
+
+// Components recording metrics
+const (
+	ComponentControlPlane    = "controlplane"
+	ComponentNATSEventSource = "nats"
+	ComponentHTTPEventSource = "http"
+	ComponentSDK             = "sdk"
+)
+
+// Reasons for dropping an event
+const (
+	ReasonInvalidEvent         = "invalid_event"
+	ReasonNoSubscription       = "no_matching_subscription"
+	ReasonNoHandler            = "no_handler"
+	ReasonFiltered             = "filtered"
+	ReasonWorkerPoolSaturated  = "worker_pool_saturated"
+	ReasonUndecodableEventData = "undecodable_event_data"
+	ReasonDuplicate            = "duplicate"
+	ReasonCancelled            = "cancelled"
+)
+
+// Results of handled events and forwarded logs
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Attribute keys used to label the recorded metrics
+const (
+	AttributeComponent = attribute.Key("component")
+	AttributeEventType = attribute.Key("event_type")
+	AttributeProject   = attribute.Key("project")
+	AttributeStage     = attribute.Key("stage")
+	AttributeResult    = attribute.Key("result")
+	AttributeReason    = attribute.Key("reason")
+)
+
+// Metrics bundles the instruments used by the sdk and the control plane connector components
+// to record the processing of events. A nil *Metrics does not record anything
+type Metrics struct {
+	eventsReceived metric.Int64Counter
+	eventsDropped  metric.Int64Counter
+	eventsHandled  metric.Int64Counter
+	taskDuration   metric.Float64Histogram
+	logsForwarded  metric.Int64Counter
+}
+
+// New creates the instruments using the given meter provider.
+// Instruments that cannot be created are skipped when recording
+func New(meterProvider metric.MeterProvider) *Metrics {
+	meter := meterProvider.Meter(meterName)
+	m := &Metrics{}
+	m.eventsReceived, _ = meter.Int64Counter("keptn.sdk.events.received",
+		metric.WithDescription("Number of events received from the Keptn control plane"), metric.WithUnit("{event}"))
+	m.eventsDropped, _ = meter.Int64Counter("keptn.sdk.events.dropped",
+		metric.WithDescription("Number of received events that have not been handled"), metric.WithUnit("{event}"))
+	m.eventsHandled, _ = meter.Int64Counter("keptn.sdk.events.handled",
+		metric.WithDescription("Number of events handled by a task handler"), metric.WithUnit("{event}"))
+	m.taskDuration, _ = meter.Float64Histogram("keptn.sdk.task.duration",
+		metric.WithDescription("Duration of the execution of task handlers"), metric.WithUnit("s"))
+	m.logsForwarded, _ = meter.Int64Counter("keptn.sdk.logs.forwarded",
+		metric.WithDescription("Number of log entries forwarded to the Keptn log ingestion API"), metric.WithUnit("{log}"))
+	return m
+}
+
+// NewDefault creates the instruments using the global meter provider
+func NewDefault() *Metrics {
+	return New(otel.GetMeterProvider())
+}
+
+// EventReceived records the reception of the given event by the given component
+func (m *Metrics) EventReceived(ctx context.Context, component string, event models.KeptnContextExtendedCE) {
+	if m == nil || m.eventsReceived == nil {
+		return
+	}
+	m.eventsReceived.Add(ctx, 1, metric.WithAttributes(append(EventAttributes(event), AttributeComponent.String(component))...))
+}
+
+// EventDropped records that the given event has been dropped by the given component for the given reason
+func (m *Metrics) EventDropped(ctx context.Context, component string, event models.KeptnContextExtendedCE, reason string) {
+	if m == nil || m.eventsDropped == nil {
+		return
+	}
+	m.eventsDropped.Add(ctx, 1, metric.WithAttributes(append(EventAttributes(event), AttributeComponent.String(component), AttributeReason.String(reason))...))
+}
+
+// EventHandled records that the given event has been handled with the given result and how long the handling took
+func (m *Metrics) EventHandled(ctx context.Context, event models.KeptnContextExtendedCE, result string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	attributes := metric.WithAttributes(append(EventAttributes(event), AttributeResult.String(result))...)
+	if m.eventsHandled != nil {
+		m.eventsHandled.Add(ctx, 1, attributes)
+	}
+	if m.taskDuration != nil {
+		m.taskDuration.Record(ctx, duration.Seconds(), attributes)
+	}
+}
+
+// LogForwarded records that the log message contained in the given event has been forwarded with the given result
+func (m *Metrics) LogForwarded(ctx context.Context, event models.KeptnContextExtendedCE, result string) {
+	if m == nil || m.logsForwarded == nil {
+		return
+	}
+	m.logsForwarded.Add(ctx, 1, metric.WithAttributes(append(EventAttributes(event), AttributeResult.String(result))...))
+}
+
+// EventAttributes returns the event type, project and stage of the given event as attributes
+func EventAttributes(event models.KeptnContextExtendedCE) []attribute.KeyValue {
+	eventType := ""
+	if event.Type != nil {
+		eventType = *event.Type
+	}
+	eventData := keptnv2.EventData{}
+	_ = keptnv2.EventDataAs(event, &eventData)
+	return []attribute.KeyValue{
+		AttributeEventType.String(eventType),
+		AttributeProject.String(eventData.Project),
+		AttributeStage.String(eventData.Stage),
+	}
+}
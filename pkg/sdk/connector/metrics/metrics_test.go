@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func newTestEvent() models.KeptnContextExtendedCE {
+	return models.KeptnContextExtendedCE{
+		Data: map[string]interface{}{"project": "prj", "stage": "stg", "service": "svc"},
+		Type: strutils.Stringp("sh.keptn.event.faketask.triggered"),
+	}
+}
+
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	rm := metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	result := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			result[m.Name] = m.Data
+		}
+	}
+	return result
+}
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	ctx := context.Background()
+
+	m.EventReceived(ctx, ComponentNATSEventSource, newTestEvent())
+	m.EventReceived(ctx, ComponentNATSEventSource, newTestEvent())
+	m.EventDropped(ctx, ComponentControlPlane, newTestEvent(), ReasonNoSubscription)
+	m.EventHandled(ctx, newTestEvent(), ResultFailure, 2*time.Second)
+	m.LogForwarded(ctx, newTestEvent(), ResultSuccess)
+
+	data := collect(t, reader)
+
+	received := data["keptn.sdk.events.received"].(metricdata.Sum[int64])
+	require.Len(t, received.DataPoints, 1)
+	require.Equal(t, int64(2), received.DataPoints[0].Value)
+	require.Equal(t, attribute.NewSet(
+		AttributeEventType.String("sh.keptn.event.faketask.triggered"),
+		AttributeProject.String("prj"),
+		AttributeStage.String("stg"),
+		AttributeComponent.String(ComponentNATSEventSource),
+	), received.DataPoints[0].Attributes)
+
+	dropped := data["keptn.sdk.events.dropped"].(metricdata.Sum[int64])
+	reason, _ := dropped.DataPoints[0].Attributes.Value(AttributeReason)
+	require.Equal(t, ReasonNoSubscription, reason.AsString())
+
+	handled := data["keptn.sdk.events.handled"].(metricdata.Sum[int64])
+	result, _ := handled.DataPoints[0].Attributes.Value(AttributeResult)
+	require.Equal(t, ResultFailure, result.AsString())
+
+	duration := data["keptn.sdk.task.duration"].(metricdata.Histogram[float64])
+	require.Equal(t, float64(2), duration.DataPoints[0].Sum)
+
+	forwarded := data["keptn.sdk.logs.forwarded"].(metricdata.Sum[int64])
+	require.Equal(t, int64(1), forwarded.DataPoints[0].Value)
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	var m *Metrics
+	require.NotPanics(t, func() {
+		m.EventReceived(context.Background(), ComponentSDK, newTestEvent())
+		m.EventDropped(context.Background(), ComponentSDK, newTestEvent(), ReasonNoHandler)
+		m.EventHandled(context.Background(), newTestEvent(), ResultSuccess, time.Second)
+		m.LogForwarded(context.Background(), newTestEvent(), ResultSuccess)
+	})
+}
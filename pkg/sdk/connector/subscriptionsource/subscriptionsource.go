@@ -167,6 +167,7 @@ func (s *UniformSubscriptionSource) ping(registrationID string, subscriptionC ch
 // as an Keptn integration to the control plane
 type FixedSubscriptionSource struct {
 	fixedSubscriptions []models.EventSubscription
+	quitC              chan struct{}
 }
 
 // WithFixedSubscriptions adds a fixed list of subscriptions to the FixedSubscriptionSource
@@ -178,7 +179,7 @@ func WithFixedSubscriptions(subscriptions ...models.EventSubscription) func(s *F
 
 // NewFixedSubscriptionSource creates a new instance of FixedSubscriptionSource
 func NewFixedSubscriptionSource(options ...func(source *FixedSubscriptionSource)) *FixedSubscriptionSource {
-	fss := &FixedSubscriptionSource{fixedSubscriptions: []models.EventSubscription{}}
+	fss := &FixedSubscriptionSource{fixedSubscriptions: []models.EventSubscription{}, quitC: make(chan struct{}, 1)}
 	for _, o := range options {
 		o(fss)
 	}
@@ -187,9 +188,18 @@ func NewFixedSubscriptionSource(options ...func(source *FixedSubscriptionSource)
 
 func (s FixedSubscriptionSource) Start(ctx context.Context, data types.RegistrationData, c chan []models.EventSubscription, errC chan error, wg *sync.WaitGroup) error {
 	go func() {
-		c <- s.fixedSubscriptions
-		<-ctx.Done()
-		wg.Done()
+		defer wg.Done()
+		select {
+		case c <- s.fixedSubscriptions:
+		case <-ctx.Done():
+			return
+		case <-s.quitC:
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case <-s.quitC:
+		}
 	}()
 	return nil
 }
@@ -199,5 +209,9 @@ func (s FixedSubscriptionSource) Register(integration models.Integration) (strin
 }
 
 func (s FixedSubscriptionSource) Stop() error {
+	select {
+	case s.quitC <- struct{}{}:
+	default:
+	}
 	return nil
 }
@@ -246,6 +246,18 @@ func TestFixedSubscriptionSource_CallsWaitGroup(t *testing.T) {
 	wg.Wait()
 }
 
+func TestFixedSubscriptionSource_StopCallsWaitGroup(t *testing.T) {
+	fss := NewFixedSubscriptionSource()
+	subchan := make(chan []models.EventSubscription)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	fss.Start(context.TODO(), types.RegistrationData{}, subchan, make(chan error), wg)
+	<-subchan
+	require.NoError(t, fss.Stop())
+	wg.Wait()
+}
+
 func TestFixedSubscriptionSourcer_Register(t *testing.T) {
 	fss := NewFixedSubscriptionSource()
 	initialRegistrationData := types.RegistrationData{}
@@ -24,4 +24,9 @@ type EventSenderKeyType struct{}
 
 var EventSenderKey = EventSenderKeyType{}
 
+type IntegrationIDKeyType struct{}
+
+// IntegrationIDKey is the context key of the ID the integration has been registered with
+var IntegrationIDKey = IntegrationIDKeyType{}
+
 type EventSender func(ce models.KeptnContextExtendedCE) error
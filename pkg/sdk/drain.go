@@ -0,0 +1,119 @@
+package sdk
+
+import (
+	"errors"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"github.com/keptn/go-utils/pkg/sdk/connector/types"
+)
+
+// defaultDrainGracePeriod is the default of DrainOptions.GracePeriod
+const defaultDrainGracePeriod = 5 * time.Second
+
+// ErrDrainTimeout is the cause of the context passed to a ContextTaskHandler, as well as the error reported in the
+// errored .finished event, if a task has not finished before the drain timeout expired during shutdown
+var ErrDrainTimeout = errors.New("task did not finish before the service shut down")
+
+// DrainOptions configures how the sdk waits for running tasks to finish when shutting down gracefully
+type DrainOptions struct {
+	// Timeout is the maximum duration the sdk waits for running tasks to finish, including the GracePeriod.
+	// When the timeout expires, an errored .finished event is sent for every .triggered event that is still being
+	// processed, so that the corresponding sequences do not hang, and Start returns.
+	// Per default (zero value), the sdk waits until all tasks are finished
+	Timeout time.Duration
+	// GracePeriod is the time waited after the control plane has stopped, so that the events received between
+	// the shutdown signal and this point are accounted for. Defaults to 5 seconds
+	GracePeriod time.Duration
+}
+
+// WithDrain configures how the sdk waits for running tasks to finish when shutting down gracefully
+func WithDrain(options DrainOptions) KeptnOption {
+	return func(k *Keptn) {
+		if options.GracePeriod == 0 {
+			options.GracePeriod = defaultDrainGracePeriod
+		}
+		k.drainOptions = options
+	}
+}
+
+// InFlightTasks returns the events that are currently being processed by a task handler or waiting for a free
+// worker of a worker pool, e.g. to report which tasks are holding up the shutdown of the service
+func (k *Keptn) InFlightTasks() []InFlightTask {
+	return k.runningTasks.List()
+}
+
+// Draining reports whether the sdk is shutting down and waiting for the running tasks to finish
+func (k *Keptn) Draining() bool {
+	return k.draining.Load()
+}
+
+// drain waits for the running tasks to finish. If they do not finish before the drain timeout expires, they are abandoned
+func (k *Keptn) drain(wg wgInterface) {
+	k.draining.Store(true)
+	var deadline <-chan time.Time
+	if k.drainOptions.Timeout > 0 {
+		deadline = time.After(k.drainOptions.Timeout)
+	}
+
+	select {
+	case <-time.After(k.drainOptions.GracePeriod):
+	case <-deadline:
+		k.abandonRunningTasks()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-deadline:
+		k.abandonRunningTasks()
+	}
+}
+
+// abandonRunningTasks cancels all running tasks and responds to their .triggered events with errored .finished events
+func (k *Keptn) abandonRunningTasks() {
+	tasks := k.runningTasks.Abandon(ErrDrainTimeout)
+	if len(tasks) > 0 {
+		k.logger.Warnf("Drain timeout expired with %d unfinished task(s)", len(tasks))
+	}
+	for _, task := range tasks {
+		k.reportAbandonedTask(task)
+	}
+}
+
+func (k *Keptn) reportAbandonedTask(task *runningTask) {
+	event := task.event
+	if !keptnv2.IsTaskEventType(*event.Type) || !keptnv2.IsTriggeredEventType(*event.Type) {
+		return
+	}
+	handler, ok := k.taskRegistry.Contains(*event.Type)
+	if !ok || !k.automaticEventResponse || handler.taskHandlerOpts.SkipAutomaticResponse {
+		k.logger.Warnf("Task for event %s has not finished before shutdown", event.ID)
+		return
+	}
+	eventSender, ok := task.ctx.Value(types.EventSenderKey).(controlplane.EventSender)
+	if !ok {
+		k.logger.Errorf("Unable to get event sender. Skip reporting unfinished task for event %s", event.ID)
+		return
+	}
+	errorEvent, err := keptnv2.CreateErrorEvent(k.source, event, nil, &keptnv2.Error{
+		StatusType: keptnv2.StatusErrored,
+		ResultType: keptnv2.ResultFailed,
+		Message:    ErrDrainTimeout.Error(),
+		Err:        ErrDrainTimeout,
+	})
+	if err != nil {
+		k.logger.Errorf("Unable to create '.error' event: %v", err)
+		return
+	}
+	k.completeIdempotencyRecord(event, errorEvent)
+	if err := tracingEventSender(task.ctx, k.outboxSender(eventSender))(*errorEvent); err != nil {
+		k.logger.Errorf("Unable to send '.error' event: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"github.com/keptn/go-utils/pkg/sdk/connector/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newDrainTestKeptn(handler ContextTaskHandler, options TaskHandlerOptions) *FakeKeptn {
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.syncProcessing = false
+	fakeKeptn.AddContextTaskEventHandler("sh.keptn.event.faketask.triggered", handler, options)
+	WithDrain(DrainOptions{Timeout: 100 * time.Millisecond})(fakeKeptn.Keptn)
+	return fakeKeptn
+}
+
+func startDrainTestEvent(t *testing.T, fakeKeptn *FakeKeptn, started chan struct{}) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+	ctx := context.WithValue(context.TODO(), types.EventSenderKey, controlplane.EventSender(fakeKeptn.fakeSender))
+	ctx = context.WithValue(ctx, gracefulShutdownKey, wg)
+	require.NoError(t, fakeKeptn.Keptn.OnEvent(ctx, newTestTaskTriggeredEvent()))
+	<-started
+	return wg
+}
+
+func Test_Drain_UnfinishedTasksAreReportedAsErrored(t *testing.T) {
+	dir := t.TempDir()
+	content, err := json.Marshal(newTestTaskTriggeredEvent())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "event.json"), content, 0600))
+
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	handler := &ContextTaskHandlerMock{ExecuteFunc: func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return FakeTaskData{}, nil
+	}}
+	output := &syncBuffer{}
+	keptn, err := NewLocalKeptn("local", DefaultConfig(), LocalOptions{EventsDirectory: dir, Output: output},
+		WithContextTaskHandler("sh.keptn.event.faketask.triggered", handler),
+		WithDrain(DrainOptions{Timeout: 300 * time.Millisecond, GracePeriod: 10 * time.Millisecond}))
+	require.NoError(t, err)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- keptn.Start()
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("task handler has not been called")
+	}
+
+	inFlight := keptn.InFlightTasks()
+	require.Len(t, inFlight, 1)
+	require.Equal(t, "sh.keptn.event.faketask.triggered", inFlight[0].EventType)
+	require.False(t, keptn.Draining())
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGTERM))
+	require.Eventually(t, keptn.Draining, time.Second, 10*time.Millisecond)
+
+	// the shutdown signal does not cancel the task, only the expiry of the drain timeout does
+	select {
+	case cause := <-causes:
+		t.Fatalf("task has been cancelled before the drain timeout expired: %v", cause)
+	case <-time.After(100 * time.Millisecond):
+	}
+	require.ErrorIs(t, <-causes, ErrDrainTimeout)
+	require.NoError(t, <-errs)
+
+	// the .finished event of the task handler returning after the drain timeout is not sent anymore
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "sh.keptn.event.faketask.started")
+	require.Contains(t, lines[1], "sh.keptn.event.faketask.finished")
+	require.Contains(t, lines[1], `"status":"errored"`)
+	require.Contains(t, lines[1], `"result":"fail"`)
+	require.Empty(t, keptn.InFlightTasks())
+}
+
+func Test_Drain_TasksWaitingForWorkerAreReportedAsErrored(t *testing.T) {
+	started := make(chan struct{})
+	executed := atomic.Int32{}
+	handler := &ContextTaskHandlerMock{ExecuteFunc: func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		executed.Add(1)
+		close(started)
+		<-ctx.Done()
+		return FakeTaskData{}, nil
+	}}
+	fakeKeptn := newDrainTestKeptn(handler, TaskHandlerOptions{})
+	WithWorkerPool(WorkerPoolOptions{MaxWorkers: 1, QueueSize: 1})(fakeKeptn.Keptn)
+	wg := startDrainTestEvent(t, fakeKeptn, started)
+	ctx := context.WithValue(context.TODO(), types.EventSenderKey, controlplane.EventSender(fakeKeptn.fakeSender))
+	ctx = context.WithValue(ctx, gracefulShutdownKey, wg)
+	require.NoError(t, fakeKeptn.Keptn.OnEvent(ctx, newTestTaskTriggeredEvent()))
+	require.Len(t, fakeKeptn.Keptn.InFlightTasks(), 2)
+
+	fakeKeptn.Keptn.drain(wg)
+	wg.Wait()
+
+	require.Equal(t, int32(1), executed.Load())
+	fakeKeptn.AssertNumberOfEventSent(t, 3)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+	for i := 1; i < 3; i++ {
+		fakeKeptn.AssertSentEventType(t, i, "sh.keptn.event.faketask.finished")
+		fakeKeptn.AssertSentEventStatus(t, i, v0_2_0.StatusErrored)
+	}
+	require.Empty(t, fakeKeptn.Keptn.InFlightTasks())
+	require.Equal(t, WorkerPoolStatus{MaxWorkers: 1, QueueSize: 1}, *fakeKeptn.Keptn.WorkerPoolStatus().Global)
+}
+
+func Test_Drain_NoResponseWithoutAutomaticResponse(t *testing.T) {
+	started := make(chan struct{})
+	handler := &ContextTaskHandlerMock{ExecuteFunc: func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		close(started)
+		<-ctx.Done()
+		return nil, nil
+	}}
+	fakeKeptn := newDrainTestKeptn(handler, TaskHandlerOptions{SkipAutomaticResponse: true})
+	wg := startDrainTestEvent(t, fakeKeptn, started)
+
+	fakeKeptn.Keptn.drain(wg)
+	wg.Wait()
+
+	fakeKeptn.AssertNumberOfEventSent(t, 0)
+}
+
+func Test_Drain_WaitsForGracePeriodAndRunningTasks(t *testing.T) {
+	fakeKeptn := NewFakeKeptn("fake")
+	WithDrain(DrainOptions{GracePeriod: 10 * time.Millisecond})(fakeKeptn.Keptn)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	time.AfterFunc(50*time.Millisecond, wg.Done)
+
+	start := time.Now()
+	fakeKeptn.Keptn.drain(wg)
+
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.Less(t, time.Since(start), defaultDrainGracePeriod)
+}
@@ -0,0 +1,71 @@
+package sdk
+
+import "strings"
+
+// eventTypeWildcard is the wildcard that can be used in event type patterns.
+// Within a pattern, the wildcard matches exactly one token of an event type, e.g. sh.keptn.event.*.triggered matches
+// sh.keptn.event.deployment.triggered. As the last token of a pattern, the wildcard matches one or more tokens,
+// e.g. sh.keptn.event.deployment.* matches sh.keptn.event.deployment.triggered as well as sh.keptn.event.deployment.status.changed
+const eventTypeWildcard = "*"
+
+// natsMultiTokenWildcard is the NATS wildcard matching one or more tokens at the end of a subject
+const natsMultiTokenWildcard = ">"
+
+// isEventTypePattern checks whether the given event type contains a wildcard
+func isEventTypePattern(eventType string) bool {
+	for _, token := range strings.Split(eventType, ".") {
+		if token == eventTypeWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// matchEventType checks whether the given event type matches the given pattern
+func matchEventType(pattern string, eventType string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	eventTypeTokens := strings.Split(eventType, ".")
+	for i, token := range patternTokens {
+		if i >= len(eventTypeTokens) {
+			return false
+		}
+		if token == eventTypeWildcard {
+			if i == len(patternTokens)-1 {
+				return true
+			}
+			continue
+		}
+		if token != eventTypeTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(eventTypeTokens)
+}
+
+// isMoreSpecific checks whether the pattern a is more specific than the pattern b. The patterns are compared token by token
+// from left to right: at the first position where one pattern contains a literal token and the other one the wildcard,
+// the pattern with the literal token is more specific. Remaining ties are broken by the number of tokens and lexical order
+func isMoreSpecific(a string, b string) bool {
+	aTokens := strings.Split(a, ".")
+	bTokens := strings.Split(b, ".")
+	for i := 0; i < len(aTokens) && i < len(bTokens); i++ {
+		aWildcard := aTokens[i] == eventTypeWildcard
+		bWildcard := bTokens[i] == eventTypeWildcard
+		if aWildcard != bWildcard {
+			return bWildcard
+		}
+	}
+	if len(aTokens) != len(bTokens) {
+		return len(aTokens) > len(bTokens)
+	}
+	return a < b
+}
+
+// natsSubject translates the given event type pattern into the corresponding NATS subject
+func natsSubject(pattern string) string {
+	tokens := strings.Split(pattern, ".")
+	if tokens[len(tokens)-1] == eventTypeWildcard {
+		tokens[len(tokens)-1] = natsMultiTokenWildcard
+	}
+	return strings.Join(tokens, ".")
+}
@@ -0,0 +1,76 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_matchEventType(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		eventType string
+		want      bool
+	}{
+		{"*", "sh.keptn.event.deployment.triggered", true},
+		{"sh.keptn.event.*.triggered", "sh.keptn.event.deployment.triggered", true},
+		{"sh.keptn.event.*.triggered", "sh.keptn.event.deployment.finished", false},
+		{"sh.keptn.event.*.triggered", "sh.keptn.event.dev.delivery.triggered", false},
+		{"sh.keptn.event.deployment.*", "sh.keptn.event.deployment.triggered", true},
+		{"sh.keptn.event.deployment.*", "sh.keptn.event.deployment.status.changed", true},
+		{"sh.keptn.event.deployment.*", "sh.keptn.event.deployment", false},
+		{"sh.keptn.event.deployment.*", "sh.keptn.event.test.triggered", false},
+		{"sh.keptn.event.deployment.triggered", "sh.keptn.event.deployment.triggered", true},
+		{"sh.keptn.event.deployment.triggered", "sh.keptn.event.deployment.triggered.foo", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.eventType, func(t *testing.T) {
+			require.Equal(t, tt.want, matchEventType(tt.pattern, tt.eventType))
+		})
+	}
+}
+
+func Test_natsSubject(t *testing.T) {
+	require.Equal(t, ">", natsSubject("*"))
+	require.Equal(t, "sh.keptn.event.*.triggered", natsSubject("sh.keptn.event.*.triggered"))
+	require.Equal(t, "sh.keptn.event.deployment.>", natsSubject("sh.keptn.event.deployment.*"))
+	require.Equal(t, "sh.keptn.event.deployment.triggered", natsSubject("sh.keptn.event.deployment.triggered"))
+}
+
+func Test_taskRegistry_ContainsMostSpecificMatch(t *testing.T) {
+	registry := newTaskMap()
+	for _, pattern := range []string{"*", "sh.keptn.event.*", "sh.keptn.event.*.triggered", "sh.keptn.event.deployment.*", "sh.keptn.event.deployment.triggered"} {
+		registry.Add(pattern, taskEntry{taskHandler: namedTaskHandler(pattern)})
+	}
+
+	tests := []struct {
+		eventType string
+		want      string
+	}{
+		{"sh.keptn.event.deployment.triggered", "sh.keptn.event.deployment.triggered"},
+		{"sh.keptn.event.deployment.finished", "sh.keptn.event.deployment.*"},
+		{"sh.keptn.event.test.triggered", "sh.keptn.event.*.triggered"},
+		{"sh.keptn.event.test.finished", "sh.keptn.event.*"},
+		{"sh.keptn.log.error", "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			entry, ok := registry.Contains(tt.eventType)
+			require.True(t, ok)
+			require.Equal(t, namedTaskHandler(tt.want), entry.taskHandler)
+		})
+	}
+}
+
+func Test_taskRegistry_ContainsNoMatch(t *testing.T) {
+	registry := newTaskMap()
+	registry.Add("sh.keptn.event.*.triggered", taskEntry{})
+	_, ok := registry.Contains("sh.keptn.event.deployment.finished")
+	require.False(t, ok)
+}
+
+type namedTaskHandler string
+
+func (n namedTaskHandler) Execute(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+	return nil, nil
+}
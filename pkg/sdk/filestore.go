@@ -0,0 +1,34 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// readJSONFile decodes the content of the file with the given path into v.
+// A file that does not exist or is empty leaves v unchanged
+func readJSONFile(path string, v interface{}) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(content) == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+// writeJSONFile encodes v as JSON into a temporary file which then replaces the file with the given path,
+// so that the file is never left in a partially written state
+func writeJSONFile(path string, v interface{}) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, path)
+}
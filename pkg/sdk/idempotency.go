@@ -0,0 +1,358 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
+)
+
+const (
+	defaultIdempotencyRetention = 24 * time.Hour
+	defaultIdempotencyLease     = time.Hour
+	// idempotencySweepInterval is the interval expired idempotency records are removed in
+	idempotencySweepInterval = 10 * time.Minute
+)
+
+// IdempotencyRecord is the record kept for every processed .triggered event
+type IdempotencyRecord struct {
+	// Key identifies the processed event and is derived from its event ID and triggered ID
+	Key string `json:"key"`
+	// EventType is the type of the processed event
+	EventType string `json:"eventType"`
+	// CreatedAt is the point in time the processing of the event started
+	CreatedAt time.Time `json:"createdAt"`
+	// Finished is set as soon as the processing of the event is done
+	Finished bool `json:"finished"`
+	// LeaseExpiresAt is the point in time after which an unfinished record may be reclaimed by a redelivery of the event,
+	// e.g. because the service crashed while processing it. The records of pending tasks are never reclaimed
+	LeaseExpiresAt time.Time `json:"leaseExpiresAt,omitempty"`
+	// FinishedEventData is the data of the .finished event that has been sent in response to the event, if any
+	FinishedEventData interface{} `json:"finishedEventData,omitempty"`
+}
+
+// pending checks whether the record has been kept for a pending task, see idempotencyReservation.keep
+func (r IdempotencyRecord) pending() bool {
+	return !r.Finished && r.LeaseExpiresAt.IsZero()
+}
+
+// reclaimable checks whether the record belongs to an event whose processing has neither finished nor been renewed until the given point in time
+func (r IdempotencyRecord) reclaimable(now time.Time) bool {
+	return !r.Finished && !r.LeaseExpiresAt.IsZero() && now.After(r.LeaseExpiresAt)
+}
+
+// IdempotencyStore persists the IdempotencyRecords of processed events
+type IdempotencyStore interface {
+	// Add stores the given record, unless a record with the same key already exists that is finished or whose lease
+	// has not expired at the creation time of the given record. In that case, the existing record and false are returned
+	Add(record IdempotencyRecord) (*IdempotencyRecord, bool, error)
+	// Update replaces the stored record having the same key as the given record
+	Update(record IdempotencyRecord) error
+	// Remove removes the record with the given key. Removing a record that is not stored is not an error
+	Remove(key string) error
+	// DeleteBefore removes all records that have been created before the given point in time, except for the
+	// records of pending tasks, i.e. unfinished records without a lease, which are kept until the task is finished
+	DeleteBefore(t time.Time) error
+}
+
+// IdempotencyOptions configures how the sdk detects and handles .triggered events that have already been processed,
+// e.g. because they have been redelivered after a restart
+type IdempotencyOptions struct {
+	// Store keeps the records of the processed events
+	Store IdempotencyStore
+	// Retention is the duration records are kept in the store. Defaults to 24 hours.
+	// The records of pending tasks are kept until the task is finished, regardless of the retention
+	Retention time.Duration
+	// Lease is the duration after which the record of an event whose processing has not finished can be reclaimed by a
+	// redelivery of the event, e.g. because the service crashed while processing it. It should exceed the time the
+	// task handlers need to process an event. Defaults to 1 hour
+	Lease time.Duration
+	// ReplayFinishedEvent determines whether duplicate events are answered with the previously sent .finished event.
+	// If not set, duplicate events are skipped
+	ReplayFinishedEvent bool
+}
+
+// WithIdempotency enables the detection of duplicate .triggered events.
+// Duplicate events are not passed to the task handler, but skipped or answered with the previously computed .finished event
+func WithIdempotency(options IdempotencyOptions) KeptnOption {
+	return func(k *Keptn) {
+		if options.Retention <= 0 {
+			options.Retention = defaultIdempotencyRetention
+		}
+		if options.Lease <= 0 {
+			options.Lease = defaultIdempotencyLease
+		}
+		k.idempotency = &options
+	}
+}
+
+func idempotencyKey(event models.KeptnContextExtendedCE) string {
+	return event.ID + "/" + event.Triggeredid
+}
+
+// idempotencyReservation is the record reserved for an event that is being processed. Unless the reservation is
+// completed or kept for a pending task, it is released when the processing ends, so that a redelivery of the event is processed again
+type idempotencyReservation struct {
+	k       *Keptn
+	event   models.KeptnContextExtendedCE
+	settled bool
+}
+
+// complete marks the reserved record as finished, see completeIdempotencyRecord
+func (r *idempotencyReservation) complete(finishedEvent *models.KeptnContextExtendedCE) {
+	if r == nil {
+		return
+	}
+	r.settled = true
+	r.k.completeIdempotencyRecord(r.event, finishedEvent)
+}
+
+// keep keeps the reserved record without a lease until the pending task of the event is completed
+func (r *idempotencyReservation) keep() {
+	if r == nil {
+		return
+	}
+	r.settled = true
+	if err := r.k.idempotency.Store.Update(IdempotencyRecord{Key: idempotencyKey(r.event), EventType: *r.event.Type, CreatedAt: time.Now()}); err != nil {
+		r.k.logger.Errorf("Unable to update idempotency record for event %s: %v", r.event.ID, err)
+	}
+}
+
+// release removes the reserved record, unless it has been completed or kept. The records of abandoned tasks
+// are left to the drain, which reports them as errored
+func (r *idempotencyReservation) release() {
+	if r == nil || r.settled || r.k.runningTasks.Abandoned(r.event) {
+		return
+	}
+	if err := r.k.idempotency.Store.Remove(idempotencyKey(r.event)); err != nil {
+		r.k.logger.Errorf("Unable to release idempotency record for event %s: %v", r.event.ID, err)
+	}
+}
+
+// reserveIdempotencyRecord stores a record for the given event. If the event has already been processed (or is
+// currently being processed), the duplicate is handled according to the idempotency options and false is returned.
+// The returned reservation is nil if idempotency is not enabled for the event
+func (k *Keptn) reserveIdempotencyRecord(ctx context.Context, eventSender controlplane.EventSender, event models.KeptnContextExtendedCE, autoResponse bool) (*idempotencyReservation, bool) {
+	if k.idempotency == nil || !keptnv2.IsTriggeredEventType(*event.Type) {
+		return nil, true
+	}
+	now := time.Now()
+	existing, added, err := k.idempotency.Store.Add(IdempotencyRecord{
+		Key:            idempotencyKey(event),
+		EventType:      *event.Type,
+		CreatedAt:      now,
+		LeaseExpiresAt: now.Add(k.idempotency.Lease),
+	})
+	if err != nil {
+		// rather process an event twice than not at all
+		k.logger.Errorf("Unable to store idempotency record for event %s: %v", event.ID, err)
+		return nil, true
+	}
+	if added {
+		return &idempotencyReservation{k: k, event: event}, true
+	}
+
+	k.metrics.EventDropped(ctx, metrics.ComponentSDK, event, metrics.ReasonDuplicate)
+	if !existing.Finished || !k.idempotency.ReplayFinishedEvent || existing.FinishedEventData == nil || !autoResponse {
+		k.logger.Infof("Event %s has already been processed. Skip processing of duplicate", event.ID)
+		return nil, false
+	}
+	k.logger.Infof("Event %s has already been processed. Replaying previously sent '.finished' event", event.ID)
+	finishedEvent, err := keptnv2.CreateFinishedEvent(k.source, event, existing.FinishedEventData)
+	if err != nil {
+		k.logger.Errorf("Unable to create '.finished' event: %v", err)
+		return nil, false
+	}
+	if err := eventSender(*finishedEvent); err != nil {
+		k.logger.Errorf("Unable to send '.finished' event: %v", err)
+	}
+	return nil, false
+}
+
+// startIdempotencySweep periodically removes the records that exceeded the configured retention until the given context is done
+func (k *Keptn) startIdempotencySweep(ctx context.Context) {
+	if k.idempotency == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(idempotencySweepInterval)
+		defer ticker.Stop()
+		for {
+			k.sweepIdempotencyRecords()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sweepIdempotencyRecords removes the records that exceeded the configured retention
+func (k *Keptn) sweepIdempotencyRecords() {
+	if err := k.idempotency.Store.DeleteBefore(time.Now().Add(-k.idempotency.Retention)); err != nil {
+		k.logger.Warnf("Unable to remove expired idempotency records: %v", err)
+	}
+}
+
+// completeIdempotencyRecord marks the record of the given event as finished and stores the data of the
+// .finished event that has been sent in response to the event
+func (k *Keptn) completeIdempotencyRecord(event models.KeptnContextExtendedCE, finishedEvent *models.KeptnContextExtendedCE) {
+	if k.idempotency == nil || !keptnv2.IsTriggeredEventType(*event.Type) {
+		return
+	}
+	record := IdempotencyRecord{
+		Key:       idempotencyKey(event),
+		EventType: *event.Type,
+		CreatedAt: time.Now(),
+		Finished:  true,
+	}
+	if finishedEvent != nil {
+		record.FinishedEventData = finishedEvent.Data
+	}
+	if err := k.idempotency.Store.Update(record); err != nil {
+		k.logger.Errorf("Unable to update idempotency record for event %s: %v", event.ID, err)
+	}
+}
+
+// InMemoryIdempotencyStore is an IdempotencyStore keeping the records in memory,
+// so that duplicates are only detected until the service restarts
+type InMemoryIdempotencyStore struct {
+	mtx     sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+// NewInMemoryIdempotencyStore creates a new InMemoryIdempotencyStore
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{records: map[string]IdempotencyRecord{}}
+}
+
+// Add stores the given record, unless a record with the same key already exists that is finished or still leased
+func (s *InMemoryIdempotencyStore) Add(record IdempotencyRecord) (*IdempotencyRecord, bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if existing, ok := s.records[record.Key]; ok && !existing.reclaimable(record.CreatedAt) {
+		return &existing, false, nil
+	}
+	s.records[record.Key] = record
+	return nil, true, nil
+}
+
+// Update replaces the stored record having the same key as the given record
+func (s *InMemoryIdempotencyStore) Update(record IdempotencyRecord) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if existing, ok := s.records[record.Key]; ok {
+		record.CreatedAt = existing.CreatedAt
+	}
+	s.records[record.Key] = record
+	return nil
+}
+
+// Remove removes the record with the given key
+func (s *InMemoryIdempotencyStore) Remove(key string) error {
+	s.remove(key)
+	return nil
+}
+
+// remove removes the record with the given key and returns whether it has been stored
+func (s *InMemoryIdempotencyStore) remove(key string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	_, ok := s.records[key]
+	delete(s.records, key)
+	return ok
+}
+
+// DeleteBefore removes all records that have been created before the given point in time, except for the records of pending tasks
+func (s *InMemoryIdempotencyStore) DeleteBefore(t time.Time) error {
+	s.deleteBefore(t)
+	return nil
+}
+
+// deleteBefore removes all records that have been created before the given point in time, except for the records
+// of pending tasks, and returns how many have been removed
+func (s *InMemoryIdempotencyStore) deleteBefore(t time.Time) int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	deleted := 0
+	for key, record := range s.records {
+		if record.CreatedAt.Before(t) && !record.pending() {
+			delete(s.records, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// FileIdempotencyStore is an IdempotencyStore persisting the records as JSON in a local file,
+// e.g. located on a persistent volume, so that the records survive restarts of the service
+type FileIdempotencyStore struct {
+	mtx   sync.Mutex
+	path  string
+	store *InMemoryIdempotencyStore
+}
+
+// NewFileIdempotencyStore creates a new FileIdempotencyStore persisting the records in the file with the given path.
+// Records already contained in the file are loaded
+func NewFileIdempotencyStore(path string) (*FileIdempotencyStore, error) {
+	s := &FileIdempotencyStore{path: filepath.Clean(path), store: NewInMemoryIdempotencyStore()}
+	if err := readJSONFile(s.path, &s.store.records); err != nil {
+		return nil, fmt.Errorf("could not read idempotency records: %w", err)
+	}
+	return s, nil
+}
+
+// Add stores the given record, unless a record with the same key already exists that is finished or still leased
+func (s *FileIdempotencyStore) Add(record IdempotencyRecord) (*IdempotencyRecord, bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	existing, added, _ := s.store.Add(record)
+	if !added {
+		return existing, false, nil
+	}
+	return nil, true, s.persist()
+}
+
+// Update replaces the stored record having the same key as the given record
+func (s *FileIdempotencyStore) Update(record IdempotencyRecord) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	_ = s.store.Update(record)
+	return s.persist()
+}
+
+// Remove removes the record with the given key
+func (s *FileIdempotencyStore) Remove(key string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.store.remove(key) {
+		return nil
+	}
+	return s.persist()
+}
+
+// DeleteBefore removes all records that have been created before the given point in time, except for the records of pending tasks
+func (s *FileIdempotencyStore) DeleteBefore(t time.Time) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.store.deleteBefore(t) == 0 {
+		return nil
+	}
+	return s.persist()
+}
+
+func (s *FileIdempotencyStore) persist() error {
+	s.store.mtx.Lock()
+	defer s.store.mtx.Unlock()
+	if err := writeJSONFile(s.path, s.store.records); err != nil {
+		return fmt.Errorf("could not write idempotency records: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"github.com/keptn/go-utils/pkg/sdk/connector/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhenReceivingADuplicateEvent(t *testing.T) {
+	tests := []struct {
+		name                string
+		replayFinishedEvent bool
+		wantSentEventTypes  []string
+	}{
+		{
+			name:               "duplicate is skipped",
+			wantSentEventTypes: []string{"sh.keptn.event.faketask.started", "sh.keptn.event.faketask.finished"},
+		},
+		{
+			name:                "duplicate is answered with the previous .finished event",
+			replayFinishedEvent: true,
+			wantSentEventTypes:  []string{"sh.keptn.event.faketask.started", "sh.keptn.event.faketask.finished", "sh.keptn.event.faketask.finished"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executions := 0
+			taskHandler := &TaskHandlerMock{}
+			taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+				executions++
+				return v0_2_0.EventData{Message: "deployed", Result: v0_2_0.ResultWarning}, nil
+			}
+			fakeKeptn := NewFakeKeptn("fake")
+			fakeKeptn.SetIdempotency(IdempotencyOptions{Store: NewInMemoryIdempotencyStore(), ReplayFinishedEvent: tt.replayFinishedEvent})
+			fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+
+			fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+			fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+			require.Equal(t, 1, executions)
+			fakeKeptn.AssertNumberOfEventSent(t, len(tt.wantSentEventTypes))
+			for i, eventType := range tt.wantSentEventTypes {
+				fakeKeptn.AssertSentEventType(t, i, eventType)
+			}
+			if tt.replayFinishedEvent {
+				fakeKeptn.AssertSentEventResult(t, 2, v0_2_0.ResultWarning)
+				fakeKeptn.AssertSentEvent(t, 2, func(ce models.KeptnContextExtendedCE) bool {
+					return ce.Triggeredid == "id" && ce.ID != fakeKeptn.SentEvents[1].ID
+				})
+			}
+		})
+	}
+}
+
+func Test_WhenReceivingAnEventWithDifferentID_EventIsProcessed(t *testing.T) {
+	executions := 0
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		executions++
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetIdempotency(IdempotencyOptions{Store: NewInMemoryIdempotencyStore()})
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+	otherEvent := newTestEvent("sh.keptn.event.faketask.triggered")
+	otherEvent.ID = "other-id"
+	fakeKeptn.NewEvent(otherEvent)
+
+	require.Equal(t, 2, executions)
+	fakeKeptn.AssertNumberOfEventSent(t, 4)
+}
+
+func Test_WhenStartedEventCannotBeSent_RedeliveryIsProcessed(t *testing.T) {
+	executions := 0
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		executions++
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetIdempotency(IdempotencyOptions{Store: NewInMemoryIdempotencyStore()})
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+	unavailable := true
+	sender := func(ce models.KeptnContextExtendedCE) error {
+		if unavailable {
+			return errors.New("unavailable")
+		}
+		return fakeKeptn.fakeSender(ce)
+	}
+	ctx := context.WithValue(context.TODO(), types.EventSenderKey, controlplane.EventSender(sender))
+	ctx = context.WithValue(ctx, gracefulShutdownKey, &nopWG{})
+
+	require.Nil(t, fakeKeptn.Keptn.OnEvent(ctx, newTestEvent("sh.keptn.event.faketask.triggered")))
+	unavailable = false
+	require.Nil(t, fakeKeptn.Keptn.OnEvent(ctx, newTestEvent("sh.keptn.event.faketask.triggered")))
+
+	require.Equal(t, 1, executions)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+}
+
+func Test_WhenLeaseOfUnfinishedRecordExpired_EventIsProcessedAgain(t *testing.T) {
+	store := NewInMemoryIdempotencyStore()
+	// the record of an event whose processing has been interrupted by a crash
+	_, _, err := store.Add(IdempotencyRecord{Key: idempotencyKey(newTestEvent("sh.keptn.event.faketask.triggered")), CreatedAt: time.Now().Add(-2 * time.Minute), LeaseExpiresAt: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+	executions := 0
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		executions++
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetIdempotency(IdempotencyOptions{Store: store})
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	require.Equal(t, 1, executions)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+}
+
+func Test_SweepIdempotencyRecords_RemovesExpiredRecords(t *testing.T) {
+	store := NewInMemoryIdempotencyStore()
+	_, _, _ = store.Add(IdempotencyRecord{Key: "expired", CreatedAt: time.Now().Add(-2 * time.Hour), Finished: true})
+	_, _, _ = store.Add(IdempotencyRecord{Key: "recent", CreatedAt: time.Now(), Finished: true})
+	k := newKeptn("fake", Config{}, WithIdempotency(IdempotencyOptions{Store: store, Retention: time.Hour}))
+
+	k.sweepIdempotencyRecords()
+
+	require.Len(t, store.records, 1)
+	require.Contains(t, store.records, "recent")
+}
+
+func Test_SweepIdempotencyRecords_KeepsRecordsOfPendingTasks(t *testing.T) {
+	store := NewInMemoryIdempotencyStore()
+	_, _, _ = store.Add(IdempotencyRecord{Key: "pending", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	_, _, _ = store.Add(IdempotencyRecord{Key: "crashed", CreatedAt: time.Now().Add(-2 * time.Hour), LeaseExpiresAt: time.Now().Add(-time.Hour)})
+	k := newKeptn("fake", Config{}, WithIdempotency(IdempotencyOptions{Store: store, Retention: time.Hour}))
+
+	k.sweepIdempotencyRecords()
+
+	require.Len(t, store.records, 1)
+	require.Contains(t, store.records, "pending")
+}
+
+func TestInMemoryIdempotencyStore(t *testing.T) {
+	store := NewInMemoryIdempotencyStore()
+	now := time.Now()
+
+	existing, added, err := store.Add(IdempotencyRecord{Key: "a", CreatedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Nil(t, existing)
+
+	require.NoError(t, store.Update(IdempotencyRecord{Key: "a", CreatedAt: now, Finished: true, FinishedEventData: "data"}))
+	existing, added, err = store.Add(IdempotencyRecord{Key: "a", CreatedAt: now})
+	require.NoError(t, err)
+	require.False(t, added)
+	require.True(t, existing.Finished)
+	require.Equal(t, "data", existing.FinishedEventData)
+	// the creation time of a record is not changed by an update
+	require.Equal(t, now.Add(-time.Hour), existing.CreatedAt)
+
+	require.NoError(t, store.DeleteBefore(now.Add(-time.Minute)))
+	_, added, err = store.Add(IdempotencyRecord{Key: "a", CreatedAt: now, LeaseExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// unfinished records can be reclaimed once their lease expired
+	_, added, err = store.Add(IdempotencyRecord{Key: "a", CreatedAt: now.Add(30 * time.Second)})
+	require.NoError(t, err)
+	require.False(t, added)
+	_, added, err = store.Add(IdempotencyRecord{Key: "a", CreatedAt: now.Add(2 * time.Minute)})
+	require.NoError(t, err)
+	require.True(t, added)
+
+	require.NoError(t, store.Remove("a"))
+	_, added, err = store.Add(IdempotencyRecord{Key: "a", CreatedAt: now})
+	require.NoError(t, err)
+	require.True(t, added)
+}
+
+func TestFileIdempotencyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	store, err := NewFileIdempotencyStore(path)
+	require.NoError(t, err)
+
+	_, added, err := store.Add(IdempotencyRecord{Key: "a", EventType: "sh.keptn.event.faketask.triggered", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.True(t, added)
+	require.NoError(t, store.Update(IdempotencyRecord{Key: "a", Finished: true, FinishedEventData: map[string]interface{}{"result": "pass"}}))
+	_, added, err = store.Add(IdempotencyRecord{Key: "b", CreatedAt: time.Now().Add(-2 * time.Hour), Finished: true})
+	require.NoError(t, err)
+	require.True(t, added)
+	require.NoError(t, store.DeleteBefore(time.Now().Add(-time.Hour)))
+
+	// records survive a restart
+	restored, err := NewFileIdempotencyStore(path)
+	require.NoError(t, err)
+	existing, added, err := restored.Add(IdempotencyRecord{Key: "a"})
+	require.NoError(t, err)
+	require.False(t, added)
+	require.True(t, existing.Finished)
+	require.Equal(t, map[string]interface{}{"result": "pass"}, existing.FinishedEventData)
+
+	_, added, err = restored.Add(IdempotencyRecord{Key: "b"})
+	require.NoError(t, err)
+	require.True(t, added)
+}
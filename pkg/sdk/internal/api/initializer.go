@@ -11,6 +11,7 @@ import (
 	eventsourceNats "github.com/keptn/go-utils/pkg/sdk/connector/eventsource/nats"
 	"github.com/keptn/go-utils/pkg/sdk/connector/logforwarder"
 	"github.com/keptn/go-utils/pkg/sdk/connector/logger"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
 	"github.com/keptn/go-utils/pkg/sdk/connector/nats"
 	"github.com/keptn/go-utils/pkg/sdk/connector/subscriptionsource"
 	"github.com/keptn/go-utils/pkg/sdk/internal/config"
@@ -26,9 +27,49 @@ type InitializationResult struct {
 	EventSenderCallback controlplane.EventSender
 }
 
+// InitializationOption allows to provide components that would otherwise be created by Initialize
+type InitializationOption func(*initializationOptions)
+
+type initializationOptions struct {
+	keptnAPI           keptnapi.KeptnInterface
+	keptnAPIV2         keptnapiv2.KeptnInterface
+	subscriptionSource subscriptionsource.SubscriptionSource
+	eventSource        eventsource.EventSource
+	logForwarder       logforwarder.LogForwarder
+}
+
+// WithKeptnAPI sets the API set to use instead of creating one based on the configuration
+func WithKeptnAPI(api keptnapi.KeptnInterface) InitializationOption {
+	return func(o *initializationOptions) {
+		o.keptnAPI = api
+	}
+}
+
+// WithKeptnAPIV2 sets the v2 API set to use instead of creating one based on the configuration
+func WithKeptnAPIV2(api keptnapiv2.KeptnInterface) InitializationOption {
+	return func(o *initializationOptions) {
+		o.keptnAPIV2 = api
+	}
+}
+
+// WithControlPlaneComponents sets the cp-connector components to use instead of creating them based on the configuration
+func WithControlPlaneComponents(subscriptionSource subscriptionsource.SubscriptionSource, eventSource eventsource.EventSource, logForwarder logforwarder.LogForwarder) InitializationOption {
+	return func(o *initializationOptions) {
+		o.subscriptionSource = subscriptionSource
+		o.eventSource = eventSource
+		o.logForwarder = logForwarder
+	}
+}
+
 // Initialize takes care of creating the API clients and initializing the cp-connector library based
-// on environment variables
-func Initialize(env config.EnvConfig, clientFactory HTTPClientGetter, logger logger.Logger) (*InitializationResult, error) {
+// on environment variables. The cp-connector components record their metrics using the given metrics.
+// Components passed via the given options are used instead of creating them
+func Initialize(env config.EnvConfig, clientFactory HTTPClientGetter, logger logger.Logger, metrics *metrics.Metrics, opts ...InitializationOption) (*InitializationResult, error) {
+	options := &initializationOptions{}
+	for _, o := range opts {
+		o(options)
+	}
+
 	// initialize http client
 	httpClient, err := clientFactory.Get()
 	if err != nil {
@@ -40,19 +81,36 @@ func Initialize(env config.EnvConfig, clientFactory HTTPClientGetter, logger log
 	}
 
 	// initialize api
-	api, err := apiSet(env, httpClient)
-	if err != nil {
-		return nil, fmt.Errorf("could not initialize control plane client api: %w", err)
+	api := options.keptnAPI
+	if api == nil {
+		api, err = apiSet(env, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize control plane client api: %w", err)
+		}
 	}
 
-	apiV2, err := apiSetV2(env, httpClient)
-	if err != nil {
-		return nil, fmt.Errorf("could not initialize v2 control plane client api: %w", err)
+	apiV2 := options.keptnAPIV2
+	if apiV2 == nil {
+		apiV2, err = apiSetV2(env, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize v2 control plane client api: %w", err)
+		}
 	}
 
 	// initialize api handlers and cp-connector components
-	ss, es, lf := createCPComponents(api, logger, env)
-	controlPlane := controlplane.New(ss, es, lf, controlplane.WithLogger(logger))
+	// only the components that have not been injected are created. The log forwarder is optional
+	// for injected components, so that it is only created if none have been injected
+	ss, es, lf := options.subscriptionSource, options.eventSource, options.logForwarder
+	if ss == nil && es == nil && lf == nil {
+		lf = logForwarder(api, logger, metrics)
+	}
+	if ss == nil {
+		ss = subscriptionSource(api, logger)
+	}
+	if es == nil {
+		es = eventSource(api, logger, metrics, env)
+	}
+	controlPlane := controlplane.New(ss, es, lf, controlplane.WithLogger(logger), controlplane.WithMetrics(metrics))
 
 	return &InitializationResult{
 		KeptnAPI:            api,
@@ -102,22 +160,18 @@ func getHttpScheme(env config.EnvConfig) (string, error) {
 	return parsed.Scheme, nil
 }
 
-func eventSource(apiSet keptnapi.KeptnInterface, logger logger.Logger, env config.EnvConfig) eventsource.EventSource {
+func eventSource(apiSet keptnapi.KeptnInterface, logger logger.Logger, metrics *metrics.Metrics, env config.EnvConfig) eventsource.EventSource {
 	if env.PubSubConnectionType() == config.ConnectionTypeHTTP {
-		return eventsourceHttp.New(clock.New(), eventsourceHttp.NewEventAPI(apiSet.ShipyardControlV1(), apiSet.APIV1()), eventsourceHttp.WithLogger(logger))
+		return eventsourceHttp.New(clock.New(), eventsourceHttp.NewEventAPI(apiSet.ShipyardControlV1(), apiSet.APIV1()), eventsourceHttp.WithLogger(logger), eventsourceHttp.WithMetrics(metrics))
 	}
 	natsConnector := nats.New(env.EventBrokerURL, nats.WithLogger(logger))
-	return eventsourceNats.New(natsConnector, eventsourceNats.WithLogger(logger))
+	return eventsourceNats.New(natsConnector, eventsourceNats.WithLogger(logger), eventsourceNats.WithMetrics(metrics))
 }
 
 func subscriptionSource(apiSet keptnapi.KeptnInterface, logger logger.Logger) subscriptionsource.SubscriptionSource {
 	return subscriptionsource.New(apiSet.UniformV1(), subscriptionsource.WithLogger(logger))
 }
 
-func logForwarder(apiSet keptnapi.KeptnInterface, logger logger.Logger) logforwarder.LogForwarder {
-	return logforwarder.New(apiSet.LogsV1(), logforwarder.WithLogger(logger))
-}
-
-func createCPComponents(apiSet keptnapi.KeptnInterface, logger logger.Logger, env config.EnvConfig) (subscriptionsource.SubscriptionSource, eventsource.EventSource, logforwarder.LogForwarder) {
-	return subscriptionSource(apiSet, logger), eventSource(apiSet, logger, env), logForwarder(apiSet, logger)
+func logForwarder(apiSet keptnapi.KeptnInterface, logger logger.Logger, metrics *metrics.Metrics) logforwarder.LogForwarder {
+	return logforwarder.New(apiSet.LogsV1(), logforwarder.WithLogger(logger), logforwarder.WithMetrics(metrics))
 }
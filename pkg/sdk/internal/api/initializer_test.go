@@ -5,6 +5,7 @@ import (
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
 	keptnapiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
 	"github.com/keptn/go-utils/pkg/sdk/connector/logger"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
 	"github.com/keptn/go-utils/pkg/sdk/internal/config"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -22,20 +23,20 @@ func (f *fakeHTTPClientFactory) Get() (*http.Client, error) {
 func Test_Initialize(t *testing.T) {
 	t.Run("Remote use case - invalid keptn api endpoint", func(t *testing.T) {
 		env := config.EnvConfig{KeptnAPIEndpoint: "://mynotsogoodendpoint"}
-		result, err := Initialize(env, CreateClientGetter(env), logger.NewDefaultLogger())
+		result, err := Initialize(env, CreateClientGetter(env), logger.NewDefaultLogger(), metrics.NewDefault())
 		require.Error(t, err)
 		require.Nil(t, result)
 	})
 	t.Run("Remote use case - no http address as keptn api endpoint", func(t *testing.T) {
 		env := config.EnvConfig{KeptnAPIEndpoint: "ssh://mynotsogoodendpoint"}
-		result, err := Initialize(env, CreateClientGetter(env), logger.NewDefaultLogger())
+		result, err := Initialize(env, CreateClientGetter(env), logger.NewDefaultLogger(), metrics.NewDefault())
 		require.Error(t, err)
 		require.Nil(t, result)
 
 	})
 	t.Run("Remote use case - remote api set is used", func(t *testing.T) {
 		env := config.EnvConfig{KeptnAPIEndpoint: "http://endpoint"}
-		result, err := Initialize(env, CreateClientGetter(env), logger.NewDefaultLogger())
+		result, err := Initialize(env, CreateClientGetter(env), logger.NewDefaultLogger(), metrics.NewDefault())
 		require.NoError(t, err)
 		require.NotNil(t, result.ControlPlane)
 		require.NotNil(t, result.EventSenderCallback)
@@ -46,7 +47,7 @@ func Test_Initialize(t *testing.T) {
 	})
 	t.Run("Internal Use case - internal api set is used", func(t *testing.T) {
 		env := config.EnvConfig{}
-		result, err := Initialize(env, CreateClientGetter(env), logger.NewDefaultLogger())
+		result, err := Initialize(env, CreateClientGetter(env), logger.NewDefaultLogger(), metrics.NewDefault())
 		require.NoError(t, err)
 		require.NotNil(t, result.ControlPlane)
 		require.NotNil(t, result.EventSenderCallback)
@@ -57,13 +58,13 @@ func Test_Initialize(t *testing.T) {
 	})
 	t.Run("HTTP client creation fails", func(t *testing.T) {
 		env := config.EnvConfig{KeptnAPIEndpoint: "http://endpoint"}
-		result, err := Initialize(env, &fakeHTTPClientFactory{GetFn: func() (*http.Client, error) { return nil, fmt.Errorf("err") }}, logger.NewDefaultLogger())
+		result, err := Initialize(env, &fakeHTTPClientFactory{GetFn: func() (*http.Client, error) { return nil, fmt.Errorf("err") }}, logger.NewDefaultLogger(), metrics.NewDefault())
 		require.Error(t, err)
 		require.Nil(t, result)
 	})
 	t.Run("HTTP client creation returns nil client", func(t *testing.T) {
 		env := config.EnvConfig{KeptnAPIEndpoint: "http://endpoint"}
-		result, err := Initialize(env, &fakeHTTPClientFactory{GetFn: func() (*http.Client, error) { return nil, nil }}, logger.NewDefaultLogger())
+		result, err := Initialize(env, &fakeHTTPClientFactory{GetFn: func() (*http.Client, error) { return nil, nil }}, logger.NewDefaultLogger(), metrics.NewDefault())
 		require.NoError(t, err)
 		require.NotNil(t, result.ControlPlane)
 		require.NotNil(t, result.EventSenderCallback)
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	"log"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -46,6 +49,35 @@ func NewEnvConfig() EnvConfig {
 	return env
 }
 
+// Defaults returns the configuration envconfig yields if none of the environment variables are set,
+// i.e. the values of the default tags of EnvConfig
+func Defaults() EnvConfig {
+	var env EnvConfig
+	v := reflect.ValueOf(&env).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		def := field.Tag.Get("default")
+		if def == "" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.String:
+			v.Field(i).SetString(def)
+		case reflect.Bool:
+			value, err := strconv.ParseBool(def)
+			if err != nil {
+				panic(fmt.Sprintf("invalid default of %s: %v", field.Name, err))
+			}
+			v.Field(i).SetBool(value)
+		case reflect.Slice:
+			v.Field(i).Set(reflect.ValueOf(strings.Split(def, ",")))
+		default:
+			panic(fmt.Sprintf("unsupported type of %s: %s", field.Name, field.Type))
+		}
+	}
+	return env
+}
+
 func (env *EnvConfig) OAuthEnabled() bool {
 	clientIDAndSecretSet := env.OAuthClientID != "" && env.OAuthClientSecret != ""
 	tokenURLOrDiscoverySet := env.OauthTokenURL != "" || env.OAuthDiscovery != ""
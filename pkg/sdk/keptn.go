@@ -2,10 +2,13 @@ package sdk
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -13,12 +16,14 @@ import (
 	sdk "github.com/keptn/go-utils/pkg/sdk/internal/api"
 	"github.com/keptn/go-utils/pkg/sdk/internal/config"
 
-	"github.com/kelseyhightower/envconfig"
 	"github.com/keptn/go-utils/pkg/api/models"
 	api "github.com/keptn/go-utils/pkg/api/utils"
 	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type IKeptn interface {
@@ -31,15 +36,35 @@ type IKeptn interface {
 	// The first parameter is the "parent" event from which common information like e.g.
 	// the keptn context, task name, ... are taken and used for constructing the corresponding .started event
 	SendStartedEvent(KeptnEvent) error
+	// SendStatusChangedEvent sends a status.changed event for the given input event to the Keptn API.
+	// It can be used by long-running task handlers to report their progress while still executing.
+	// The first parameter is the "parent" event from which common information like e.g.
+	// the keptn context, task name, ... are taken and used for constructing the corresponding .status.changed event.
+	// The second parameter is the new event data to be set on the newly constructed .status.changed event
+	SendStatusChangedEvent(KeptnEvent, interface{}) error
 	// SendFinishedEvent sends a finished event for the given input event to the Keptn API.
 	// The first parameter can be seen as the "parent" event from which common information like e.g.
 	// the keptn context, task name, ... are taken and used for constructing the corresponding .finished event.
-	// The second parameter is the new event data to be set on the newly constructed .finished event
+	// The second parameter is the new event data to be set on the newly constructed .finished event.
+	// If the task of the given event is pending, it is completed
 	SendFinishedEvent(KeptnEvent, interface{}) error
 	// Logger returns the logger used by the sdk
 	// Per default DefaultLogger is used which internally just uses the go logging package
-	// Another logger can be configured using the sdk.WithLogger function
+	// Another logger can be configured using the sdk.WithLogger function.
+	// In contrast to the entries logged by the sdk itself and via EventLogger, secret values are not redacted
 	Logger() Logger
+	// EventLogger returns a logger adding the keptn context, triggered ID, event type, project, stage and service
+	// of the given event to every entry, so that entries logged while handling different events can be told apart.
+	// If the configured logger does not implement StructuredLogger, the fields are prepended to the messages
+	EventLogger(KeptnEvent) StructuredLogger
+	// GetSecret returns the value stored under the given key of the secret with the given name.
+	// The secret is looked up from mounted secret files, environment variables and the secret-service,
+	// in the order configured via WithSecrets. The returned value is redacted in the entries logged by the sdk
+	GetSecret(name string, key string) (string, error)
+	// GetTaskResult decodes the data of the most recent .finished event of the given task in the keptn context and stage
+	// of the given event into the third parameter, e.g. the deployment URIs of the deployment task for a test task.
+	// If the task has not been finished in the keptn context and stage, ErrTaskResultNotFound is returned
+	GetTaskResult(KeptnEvent, string, interface{}) error
 	// APIV1 returns API utils for all Keptn APIs
 	APIV1() api.KeptnInterface
 	// APIV2 returns API utils for all v2 Keptn APIs
@@ -55,8 +80,25 @@ type TaskHandler interface {
 	Execute(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error)
 }
 
+// ContextTaskHandler is a TaskHandler that additionally receives a context.Context for every event.
+// The context is cancelled when the drain timeout (see WithDrain) expires during a graceful shutdown, or when the
+// sequence the event belongs to is aborted, i.e. when an .invalidated event or an aborted sequence .finished event
+// with the same keptn context is received. context.Cause returns ErrDrainTimeout or ErrTaskAborted respectively.
+// Receiving the shutdown signal alone does not cancel the context
+type ContextTaskHandler interface {
+	// Execute is called whenever the actual business-logic of the service shall be executed.
+	// Thus, the core logic of the service shall be triggered/implemented in this method.
+	//
+	// Note, that the contract of the method is to return the payload of the .finished event to be sent out as well as a Error Pointer
+	// or nil, if there was no error during execution.
+	Execute(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error)
+}
+
 type Error keptnv2.Error
 
+// ErrTaskTimeout is used as error of the *Error that is reported if a task handler exceeded its configured timeout
+var ErrTaskTimeout = errors.New("task handler timed out")
+
 type KeptnEvent models.KeptnContextExtendedCE
 
 // KeptnOption can be used to configure the keptn sdk
@@ -66,17 +108,7 @@ type ResourceHandler interface {
 	GetResource(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error)
 }
 
-type resourceHandlerWrapper struct {
-	resourceHandler apiv2.ResourcesInterface
-}
-
-func (rhw *resourceHandlerWrapper) GetResource(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
-	v2Scope := apiv2.NewResourceScope().Project(scope.GetProject()).Stage(scope.GetStage()).Service(scope.GetService()).Resource(scope.GetResource())
-
-	return rhw.resourceHandler.GetResource(context.Background(), *v2Scope, apiv2.ResourcesGetResourceOptions{})
-}
-
-type healthEndpointRunner func(port string, cp *controlplane.ControlPlane)
+type healthEndpointRunner func(port string, cp *controlplane.ControlPlane, opts ...api.HealthHandlerOption)
 
 // Opaque key type used for graceful shutdown context value
 type gracefulShutdownKeyType struct{}
@@ -113,13 +145,32 @@ func WithTaskHandler(eventType string, handler TaskHandler, filters ...func(kept
 	})
 }
 
-// WithTaskEventHandler registers a handler which is responsible for processing a received .triggered event
+// WithTaskEventHandler registers a handler which is responsible for processing a received .triggered event.
+// Instead of a concrete event type, a pattern such as sh.keptn.event.*.triggered or sh.keptn.event.deployment.* can be
+// given to handle a family of events. If several patterns match an event type, the most specific one is used
 func WithTaskEventHandler(eventType string, handler TaskHandler, options TaskHandlerOptions) KeptnOption {
 	return func(k *Keptn) {
 		k.taskRegistry.Add(eventType, taskEntry{taskHandler: handler, eventFilters: options.Filters, taskHandlerOpts: options})
 	}
 }
 
+// WithContextTaskHandler registers a context aware handler which is responsible for processing a .triggered event.
+// Note, that if you want to have more control on configuring the behavior of the task handler,
+// you can use WithContextTaskEventHandler instead
+func WithContextTaskHandler(eventType string, handler ContextTaskHandler, filters ...func(keptnHandle IKeptn, event KeptnEvent) bool) KeptnOption {
+	return WithContextTaskEventHandler(eventType, handler, TaskHandlerOptions{
+		Filters:               filters,
+		SkipAutomaticResponse: false,
+	})
+}
+
+// WithContextTaskEventHandler registers a context aware handler which is responsible for processing a received .triggered event
+func WithContextTaskEventHandler(eventType string, handler ContextTaskHandler, options TaskHandlerOptions) KeptnOption {
+	return func(k *Keptn) {
+		k.taskRegistry.Add(eventType, taskEntry{contextTaskHandler: handler, eventFilters: options.Filters, taskHandlerOpts: options})
+	}
+}
+
 // WithAutomaticResponse sets the option to instruct the sdk to automatically send a .started and .finished event.
 // Per default this behavior is turned on and can be disabled with this function. Note, that this affects ALL
 // task handlers. If you want to disable automatic event responses for a specific task handler, this can be done
@@ -136,6 +187,19 @@ type TaskHandlerOptions struct {
 	Filters []func(IKeptn, KeptnEvent) bool
 	// SkipAutomaticResponse determines whether automatic sending of .started/.finished events should be skipped
 	SkipAutomaticResponse bool
+	// Timeout is the maximum duration the task handler is allowed to execute. If it is exceeded, the sdk stops
+	// waiting for the handler and treats the task as errored, i.e. an errored .finished event is sent if automatic
+	// responses are enabled and the task no longer delays a graceful shutdown. Events the handler sends via IKeptn after
+	// the timeout expired are dropped. The context passed to a ContextTaskHandler carries the respective deadline.
+	// Per default (zero value) no timeout is applied. If a RetryPolicy is set, the timeout applies to every attempt
+	Timeout time.Duration
+	// RetryPolicy determines whether and when the task handler is executed again after it returned an error.
+	// Per default (zero value) the task handler is not retried
+	RetryPolicy RetryPolicy
+	// SubscriptionFilter restricts the projects, stages and services of the events passed to the task handler.
+	// If the subscriptions are derived from the registered task handlers (see WithSubscriptionMode), the filter
+	// is also added to the subscription of the task handler
+	SubscriptionFilter models.EventSubscriptionFilter
 }
 
 // WithGracefulShutdown sets the option to ensure running tasks/handlers will finish in case of interrupt or forced termination
@@ -162,49 +226,107 @@ type Keptn struct {
 	apiV2                  apiv2.KeptnInterface
 	source                 string
 	taskRegistry           *taskRegistry
+	observerRegistry       *observerRegistry
+	workerPools            *workerPools
+	taskMiddlewares        []TaskMiddleware
+	runningTasks           *runningTasks
 	syncProcessing         bool
 	automaticEventResponse bool
 	gracefulShutdown       bool
+	tracerProvider         trace.TracerProvider
+	meterProvider          metric.MeterProvider
+	metricsHandler         http.Handler
+	metrics                *metrics.Metrics
+	idempotency            *IdempotencyOptions
+	subscriptionMode       SubscriptionMode
+	logForwarding          *LogForwardingOptions
+	drainOptions           DrainOptions
+	outboxOptions          *OutboxOptions
+	outbox                 *outbox
+	pendingTasks           PendingTaskStore
+	resourceOptions        ResourceOptions
+	secretOptions          SecretOptions
+	secretProviders        []SecretProvider
+	secretRedactor         *secretRedactor
+	draining               atomic.Bool
+	deliveries             *deliveries
+	integrationID          atomic.Value
 	logger                 Logger
 	env                    config.EnvConfig
+	httpClient             *http.Client
+	initializationOpts     []sdk.InitializationOption
 	healthEndpointRunner   healthEndpointRunner
 }
 
-// NewKeptn creates a new Keptn
+// NewKeptn creates a new Keptn based on the configuration read from environment variables.
+// If the sdk cannot be initialized, the program is terminated. Use NewKeptnWithConfig to handle initialization errors
 func NewKeptn(source string, opts ...KeptnOption) *Keptn {
+	cfg, err := ConfigFromEnv()
+	keptn := newKeptn(source, cfg, opts...)
+	if err != nil {
+		keptn.logger.Fatalf("failed to process env vars: %v", err)
+	}
+	if err := keptn.initialize(); err != nil {
+		keptn.logger.Fatalf("failed to initialize keptn sdk: %v", err)
+	}
+	return keptn
+}
+
+// NewKeptnWithConfig creates a new Keptn based on the given configuration.
+// In contrast to NewKeptn, environment variables are not considered and initialization errors are returned
+func NewKeptnWithConfig(source string, cfg Config, opts ...KeptnOption) (*Keptn, error) {
+	keptn := newKeptn(source, cfg, opts...)
+	if err := keptn.initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize keptn sdk: %w", err)
+	}
+	return keptn, nil
+}
+
+func newKeptn(source string, cfg Config, opts ...KeptnOption) *Keptn {
 	keptn := &Keptn{
 		source:                 source,
 		taskRegistry:           newTaskMap(),
+		observerRegistry:       newObserverRegistry(),
+		workerPools:            newWorkerPools(),
+		runningTasks:           newRunningTasks(),
 		automaticEventResponse: true,
 		gracefulShutdown:       true,
+		drainOptions:           DrainOptions{GracePeriod: defaultDrainGracePeriod},
 		syncProcessing:         false,
 		logger:                 newDefaultLogger(),
-		env:                    config.NewEnvConfig(),
+		env:                    cfg,
 		healthEndpointRunner:   newHealthEndpointRunner,
+		secretRedactor:         newSecretRedactor(),
+		deliveries:             newDeliveries(duplicateDeliveryWindow),
 	}
 
 	for _, opt := range opts {
 		opt(keptn)
 	}
+	keptn.logger = newRedactingLogger(keptn.logger, keptn.secretRedactor)
+	keptn.initMetrics()
+	keptn.initOutbox()
+	return keptn
+}
 
-	var env config.EnvConfig
-	if err := envconfig.Process("", &env); err != nil {
-		keptn.logger.Fatalf("failed to process env vars: %v", err)
+// initialize creates the API clients and the control plane components, unless they have been provided via options
+func (k *Keptn) initialize() error {
+	var httpClientFactory sdk.HTTPClientGetter = sdk.CreateClientGetter(k.env)
+	if k.httpClient != nil {
+		httpClientFactory = staticHTTPClientGetter{client: k.httpClient}
 	}
-
-	httpClientFactory := sdk.CreateClientGetter(env)
-	initializationResult, err := sdk.Initialize(env, httpClientFactory, keptn.logger)
+	initializationResult, err := sdk.Initialize(k.env, httpClientFactory, k.logger, k.metrics, k.initializationOpts...)
 	if err != nil {
-		keptn.logger.Fatalf("failed to initialize keptn sdk: %v", err)
+		return err
 	}
 
-	keptn.api = initializationResult.KeptnAPI
-	keptn.apiV2 = initializationResult.KeptnAPIV2
-	keptn.controlPlane = initializationResult.ControlPlane
-	keptn.eventSender = initializationResult.EventSenderCallback
-	keptn.resourceHandler = &resourceHandlerWrapper{resourceHandler: initializationResult.KeptnAPIV2.Resources()}
-
-	return keptn
+	k.api = initializationResult.KeptnAPI
+	k.apiV2 = initializationResult.KeptnAPIV2
+	k.controlPlane = initializationResult.ControlPlane
+	k.eventSender = initializationResult.EventSenderCallback
+	k.resourceHandler = newResourceHandlerWrapper(initializationResult.KeptnAPIV2.Resources(), k.resourceOptions)
+	k.initSecrets()
+	return nil
 }
 
 func (k *Keptn) OnEvent(ctx context.Context, event models.KeptnContextExtendedCE) error {
@@ -214,14 +336,39 @@ func (k *Keptn) OnEvent(ctx context.Context, event models.KeptnContextExtendedCE
 		k.logger.Errorf("Unable to get event sender. Skip processing of event %s", event.ID)
 		return nil
 	}
+	eventSender = k.outboxSender(eventSender)
+	if integrationID, ok := ctx.Value(types.IntegrationIDKey).(string); ok && integrationID != "" {
+		k.integrationID.Store(integrationID)
+	}
 
 	if event.Type == nil {
 		k.logger.Errorf("Unable to get event type. Skip processing of event %s", event.ID)
 		return nil
 	}
+	if !k.deliveries.first(event.ID) {
+		k.logger.Debugf("Event %s has already been received via another subscription. Skip processing of the event", event.ID)
+		return nil
+	}
+
+	abortSignal := isAbortSignal(event)
+	if abortSignal {
+		cancelled := k.runningTasks.Cancel(event.Shkeptncontext, ErrTaskAborted)
+		discarded := k.discardPendingTasks(event.Shkeptncontext)
+		k.logger.Infof("Received %s event: cancelled %d running and %d pending task(s) of keptn context %s", *event.Type, cancelled, discarded, event.Shkeptncontext)
+	}
+
+	observed := k.notifyObservers(ctx, event)
+	// abort signals are only passed to a task handler registered for exactly their event type,
+	// so that they do not reach handlers registered for patterns such as sh.keptn.event.deployment.*
+	if abortSignal && !k.taskRegistry.ContainsExactly(*event.Type) {
+		return nil
+	}
 
 	if !keptnv2.IsTaskEventType(*event.Type) {
-		k.logger.Errorf("Event type %s does not match format for task events. Skip Processing of event %s", *event.Type, event.ID)
+		// sequence events are received via the abort signal subscriptions without necessarily being handled
+		if !observed && !(keptnv2.IsSequenceEventType(*event.Type) && k.cancellable()) {
+			k.logger.Errorf("Event type %s does not match format for task events. Skip Processing of event %s", *event.Type, event.ID)
+		}
 		return nil
 	}
 	wg, ok := ctx.Value(gracefulShutdownKey).(wgInterface)
@@ -229,23 +376,56 @@ func (k *Keptn) OnEvent(ctx context.Context, event models.KeptnContextExtendedCE
 		k.logger.Errorf("Unable to get graceful shutdown wait group. Skip processing of event %s", event.ID)
 		return nil
 	}
+	registeredHandler, ok := k.taskRegistry.Contains(*event.Type)
+	if !ok {
+		if !observed {
+			k.logger.Debugf("No task handler registered for event type %s. Skip processing of event %s", *event.Type, event.ID)
+			k.metrics.EventDropped(ctx, metrics.ComponentSDK, event, metrics.ReasonNoHandler)
+		}
+		return nil
+	}
+	// automatic response of events is enabled if it is turned on globally, and not disabled for the specific handler
+	autoResponse := k.automaticEventResponse && !registeredHandler.taskHandlerOpts.SkipAutomaticResponse
+	registeredEventType, _ := k.taskRegistry.Match(*event.Type)
+	pools := k.workerPools.forEventType(*event.Type, registeredEventType)
+	if err := pools.enqueue(); err != nil {
+		k.logger.Errorf("Unable to process event %s: %v", event.ID, err)
+		k.metrics.EventDropped(ctx, metrics.ComponentSDK, event, metrics.ReasonWorkerPoolSaturated)
+		if autoResponse {
+			k.rejectEvent(eventSender, event, err)
+		}
+		return nil
+	}
+	// the task is registered before waiting for a free worker, so that queued events are cancelled by abort signals
+	// and reported when the drain timeout expires, just like running ones
+	spanCtx, span := k.startProcessSpan(ctx, event)
+	taskCtx, done := k.runningTasks.Add(spanCtx, event)
 	wg.Add(1)
 	k.runEventTaskAction(func() {
 		{
 			defer wg.Done()
+			defer span.End()
+			defer done()
+			if err := pools.acquire(taskCtx); err != nil {
+				k.logger.Infof("Event %s has been cancelled while waiting for a free worker: %v", event.ID, err)
+				k.metrics.EventDropped(spanCtx, metrics.ComponentSDK, event, metrics.ReasonCancelled)
+				return
+			}
+			defer pools.release()
+			defer k.recoverPanic(eventSender, event, autoResponse)
 			if handler, ok := k.taskRegistry.Contains(*event.Type); ok {
+				eventSender := tracingEventSender(spanCtx, eventSender)
+				eventSender = k.runningTasks.guardSender(event, sdkAttempt, eventSender)
 				keptnEvent := &KeptnEvent{}
 				if err := keptnv2.Decode(&event, keptnEvent); err != nil {
-					errorLogEvent, err := keptnv2.CreateErrorLogEvent(k.source, event, nil, &keptnv2.Error{Err: err, StatusType: keptnv2.StatusErrored, ResultType: keptnv2.ResultFailed})
-					if err != nil {
-						k.logger.Errorf("Unable to create '.error.log' event from '.triggered' event: %v", err)
-						return
-					}
-					// no started event sent yet, so it only makes sense to Send an error log event at this point
-					if err := eventSender(*errorLogEvent); err != nil {
-						k.logger.Errorf("Unable to send '.finished' event: %v", err)
-						return
-					}
+					k.metrics.EventDropped(spanCtx, metrics.ComponentSDK, event, metrics.ReasonInvalidEvent)
+					k.reportDecodeError(eventSender, event, &keptnv2.Error{Err: err, StatusType: keptnv2.StatusErrored, ResultType: keptnv2.ResultFailed})
+					return
+				}
+
+				if !matchesSubscriptionFilter(handler, event) {
+					k.logger.Infof("Will not handle incoming %s event not matching the subscription filter", *event.Type)
+					k.metrics.EventDropped(spanCtx, metrics.ComponentSDK, event, metrics.ReasonFiltered)
 					return
 				}
 
@@ -254,12 +434,31 @@ func (k *Keptn) OnEvent(ctx context.Context, event models.KeptnContextExtendedCE
 				for _, filterFn := range handler.eventFilters {
 					if !filterFn(k, *keptnEvent) {
 						k.logger.Infof("Will not handle incoming %s event", *event.Type)
+						k.metrics.EventDropped(spanCtx, metrics.ComponentSDK, event, metrics.ReasonFiltered)
 						return
 					}
 				}
 
-				// automatic response of events is enabled if it is turned on globally, and not disabled for the specific handler
-				autoResponse := k.automaticEventResponse && !k.taskRegistry.Get(*event.Type).taskHandlerOpts.SkipAutomaticResponse
+				// task handlers expecting typed event data get the chance to reject the event before a .started event is sent
+				if err := handler.decodeEventData(*keptnEvent); err != nil {
+					k.metrics.EventDropped(spanCtx, metrics.ComponentSDK, event, metrics.ReasonUndecodableEventData)
+					k.reportDecodeError(eventSender, event, &keptnv2.Error{
+						Err:        err,
+						StatusType: keptnv2.StatusErrored,
+						ResultType: keptnv2.ResultFailed,
+						Message:    fmt.Sprintf("unable to decode data of event %s: %v", event.ID, err),
+					})
+					return
+				}
+
+				// skip events that have already been processed, e.g. due to a redelivery after a restart
+				reservation, ok := k.reserveIdempotencyRecord(spanCtx, eventSender, event, autoResponse)
+				if !ok {
+					return
+				}
+				// the record is released on every exit that neither completes the task nor leaves it pending,
+				// so that a redelivery of the event is not skipped
+				defer reservation.release()
 
 				// only respond with .started event if the incoming event is a task.triggered event
 				if keptnv2.IsTaskEventType(*event.Type) && keptnv2.IsTriggeredEventType(*event.Type) && autoResponse {
@@ -274,10 +473,17 @@ func (k *Keptn) OnEvent(ctx context.Context, event models.KeptnContextExtendedCE
 					}
 				}
 
-				result, err := handler.taskHandler.Execute(k, *keptnEvent)
+				start := time.Now()
+				result, err := k.executeTaskWithRetry(taskCtx, eventSender, handler, *keptnEvent, autoResponse)
+				k.metrics.EventHandled(spanCtx, event, handlerResult(err), time.Since(start))
 				if err != nil {
 					k.logger.Errorf("Error during task execution %v", err.Err)
-					if autoResponse {
+					if errors.Is(err.Err, ErrTaskPanic) {
+						k.sendErrorLogEvent(eventSender, event, err.Message)
+					}
+					if !autoResponse {
+						reservation.complete(nil)
+					} else {
 						errorEvent, err := keptnv2.CreateErrorEvent(k.source, event, result, &keptnv2.Error{
 							StatusType: err.StatusType,
 							ResultType: err.ResultType,
@@ -288,6 +494,7 @@ func (k *Keptn) OnEvent(ctx context.Context, event models.KeptnContextExtendedCE
 							k.logger.Errorf("Unable to create '.error' event: %v", err)
 							return
 						}
+						reservation.complete(errorEvent)
 						if err := eventSender(*errorEvent); err != nil {
 							k.logger.Errorf("Unable to send '.error' event: %v", err)
 							return
@@ -295,18 +502,26 @@ func (k *Keptn) OnEvent(ctx context.Context, event models.KeptnContextExtendedCE
 					}
 					return
 				}
+				if pending, ok := result.(*PendingResult); ok {
+					k.suspendTask(eventSender, event, pending, autoResponse, reservation)
+					return
+				}
 				if result == nil {
 					k.logger.Infof("no finished data set by task executor for event %s. Skipping sending finished event", *event.Type)
+					reservation.complete(nil)
 				} else if keptnv2.IsTaskEventType(*event.Type) && keptnv2.IsTriggeredEventType(*event.Type) && autoResponse {
 					finishedEvent, err := keptnv2.CreateFinishedEvent(k.source, event, result)
 					if err != nil {
 						k.logger.Errorf("Unable to create '.finished' event: %v", err)
 						return
 					}
+					reservation.complete(finishedEvent)
 					if err := eventSender(*finishedEvent); err != nil {
 						k.logger.Errorf("Unable to send '.finished' event: %v", err)
 						return
 					}
+				} else {
+					reservation.complete(nil)
 				}
 			}
 		}
@@ -315,15 +530,6 @@ func (k *Keptn) OnEvent(ctx context.Context, event models.KeptnContextExtendedCE
 }
 
 func (k *Keptn) RegistrationData() controlplane.RegistrationData {
-	subscriptions := []models.EventSubscription{}
-	subjects := []string{}
-	if k.env.PubSubTopic != "" {
-		subjects = strings.Split(k.env.PubSubTopic, ",")
-	}
-
-	for _, s := range subjects {
-		subscriptions = append(subscriptions, models.EventSubscription{Event: s})
-	}
 	return controlplane.RegistrationData{
 		Name: k.source,
 		MetaData: models.MetaData{
@@ -337,19 +543,26 @@ func (k *Keptn) RegistrationData() controlplane.RegistrationData {
 				DeploymentName: k.env.K8sDeploymentName,
 			},
 		},
-		Subscriptions: subscriptions,
+		Subscriptions: k.subscriptions(),
 	}
 }
 
 func (k *Keptn) Start() error {
 	if k.env.HealthEndpointEnabled {
-		k.healthEndpointRunner(k.env.HealthEndpointPort, k.controlPlane)
+		healthOpts := []api.HealthHandlerOption{api.WithStatusDetailsFunc(k.healthStatusDetails)}
+		if k.metricsHandler != nil {
+			healthOpts = append(healthOpts, api.WithMetricsHandler(k.metricsHandler))
+		}
+		k.healthEndpointRunner(k.env.HealthEndpointPort, k.controlPlane, healthOpts...)
 	}
 	ctx, wg := k.getContext(k.gracefulShutdown)
+	k.startLogForwarding(ctx)
+	k.startOutbox(ctx)
+	k.startIdempotencySweep(ctx)
 	err := k.controlPlane.Register(ctx, k)
-	// add additional waiting time to ensure the waitGroup has been increased for all events that have been received between receiving SIGTERM and this point
-	<-time.After(5 * time.Second)
-	wg.Wait()
+	k.drain(wg)
+	k.flushOutbox()
+	k.flushForwardedLogs()
 
 	return err
 }
@@ -359,19 +572,50 @@ func (k *Keptn) GetResourceHandler() ResourceHandler {
 }
 
 func (k *Keptn) SendStartedEvent(parentEvent KeptnEvent) error {
+	return k.sendStartedEvent(parentEvent, 0)
+}
+
+func (k *Keptn) SendStatusChangedEvent(parentEvent KeptnEvent, newEventData interface{}) error {
+	return k.sendStatusChangedEvent(parentEvent, newEventData, 0)
+}
+
+func (k *Keptn) SendFinishedEvent(parentEvent KeptnEvent, newEventData interface{}) error {
+	return k.sendFinishedEvent(parentEvent, newEventData, 0)
+}
+
+func (k *Keptn) sendStartedEvent(parentEvent KeptnEvent, attempt int) error {
 	startedEvent, err := keptnv2.CreateStartedEvent(k.source, models.KeptnContextExtendedCE(parentEvent), nil)
 	if err != nil {
 		return err
 	}
-	return k.eventSender(*startedEvent)
+	return k.sendEvent(models.KeptnContextExtendedCE(parentEvent), attempt, *startedEvent)
 }
 
-func (k *Keptn) SendFinishedEvent(parentEvent KeptnEvent, newEventData interface{}) error {
+func (k *Keptn) sendStatusChangedEvent(parentEvent KeptnEvent, newEventData interface{}, attempt int) error {
+	statusChangedEvent, err := keptnv2.CreateStatusChangedEvent(k.source, models.KeptnContextExtendedCE(parentEvent), newEventData)
+	if err != nil {
+		return err
+	}
+	return k.sendEvent(models.KeptnContextExtendedCE(parentEvent), attempt, *statusChangedEvent)
+}
+
+func (k *Keptn) sendFinishedEvent(parentEvent KeptnEvent, newEventData interface{}, attempt int) error {
 	finishedEvent, err := keptnv2.CreateFinishedEvent(k.source, models.KeptnContextExtendedCE(parentEvent), newEventData)
 	if err != nil {
 		return err
 	}
-	return k.eventSender(*finishedEvent)
+	if err := k.sendEvent(models.KeptnContextExtendedCE(parentEvent), attempt, *finishedEvent); err != nil {
+		return err
+	}
+	k.completePendingTask(models.KeptnContextExtendedCE(parentEvent), *finishedEvent)
+	return nil
+}
+
+// sendEvent sends the given event, which has been created in response to the given parent event by the given attempt
+// of the task handler, to the Keptn API. Events of abandoned tasks and attempts are dropped
+func (k *Keptn) sendEvent(parentEvent models.KeptnContextExtendedCE, attempt int, event models.KeptnContextExtendedCE) error {
+	eventSender := k.runningTasks.guardSender(parentEvent, attempt, k.outboxSender(k.eventSender))
+	return tracingEventSender(k.traceContext(parentEvent), eventSender)(event)
 }
 
 // APIV1 retrieves the APIV1 client
@@ -386,9 +630,75 @@ func (k *Keptn) APIV2() apiv2.KeptnInterface {
 }
 
 func (k *Keptn) Logger() Logger {
+	if logger, ok := k.logger.(redactingLogger); ok {
+		return logger.logger
+	}
 	return k.logger
 }
 
+func (k *Keptn) EventLogger(event KeptnEvent) StructuredLogger {
+	logger := withFields(k.logger, eventFields(models.KeptnContextExtendedCE(event)))
+	return k.forwardingLogger(logger, models.KeptnContextExtendedCE(event))
+}
+
+// reportDecodeError reports that the data of the given event could not be decoded
+func (k *Keptn) reportDecodeError(eventSender controlplane.EventSender, event models.KeptnContextExtendedCE, decodeErr *keptnv2.Error) {
+	errorLogEvent, err := keptnv2.CreateErrorLogEvent(k.source, event, nil, decodeErr)
+	if err != nil {
+		k.logger.Errorf("Unable to create '.error.log' event from '.triggered' event: %v", err)
+		return
+	}
+	// no started event sent yet, so it only makes sense to Send an error log event at this point
+	if err := eventSender(*errorLogEvent); err != nil {
+		k.logger.Errorf("Unable to send '.finished' event: %v", err)
+	}
+}
+
+// executeTask executes the given task handler while respecting the timeout configured in its TaskHandlerOptions.
+// If the timeout is exceeded, an *Error is returned without waiting for the handler to return and the events
+// the handler sends afterwards are dropped
+func (k *Keptn) executeTask(ctx context.Context, handler *taskEntry, event KeptnEvent) (interface{}, *Error) {
+	attempt := k.runningTasks.BeginAttempt(models.KeptnContextExtendedCE(event))
+	timeout := handler.taskHandlerOpts.Timeout
+	if timeout <= 0 {
+		return k.runHandler(ctx, handler, event, attempt)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type executionResult struct {
+		result interface{}
+		err    *Error
+	}
+	resultC := make(chan executionResult, 1)
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		result, err := k.runHandler(ctx, handler, event, attempt)
+		resultC <- executionResult{result: result, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-resultC:
+		return r.result, r.err
+	case <-timer.C:
+		release := k.runningTasks.AbandonAttempt(models.KeptnContextExtendedCE(event), attempt)
+		go func() {
+			<-returned
+			release()
+		}()
+		return nil, &Error{
+			StatusType: keptnv2.StatusErrored,
+			ResultType: keptnv2.ResultFailed,
+			Message:    fmt.Sprintf("task handler for event %s did not finish within %s", *event.Type, timeout),
+			Err:        ErrTaskTimeout,
+		}
+	}
+}
+
 func (k *Keptn) runEventTaskAction(fn func()) {
 	if k.syncProcessing {
 		fn()
@@ -414,12 +724,48 @@ func (k *Keptn) getContext(graceful bool) (context.Context, wgInterface) {
 	return ctx, wg
 }
 
-func noOpHealthEndpointRunner(port string, cp *controlplane.ControlPlane) {}
+// WorkerPoolStatus returns the current utilization of the worker pools configured via WithWorkerPool
+// and WithEventTypeWorkerPool
+func (k *Keptn) WorkerPoolStatus() WorkerPoolsStatus {
+	return k.workerPools.status()
+}
+
+func (k *Keptn) healthStatusDetails() map[string]interface{} {
+	details := map[string]interface{}{
+		"workerPools":   k.WorkerPoolStatus(),
+		"draining":      k.Draining(),
+		"inFlightTasks": k.InFlightTasks(),
+	}
+	if k.outbox != nil {
+		details["outbox"] = k.outbox.status()
+	}
+	return details
+}
+
+// rejectEvent responds to the given event with an errored .finished event because it could not be processed
+func (k *Keptn) rejectEvent(eventSender controlplane.EventSender, event models.KeptnContextExtendedCE, reason error) {
+	errorEvent, err := keptnv2.CreateErrorEvent(k.source, event, nil, &keptnv2.Error{
+		StatusType: keptnv2.StatusErrored,
+		ResultType: keptnv2.ResultFailed,
+		Message:    fmt.Sprintf("event %s was rejected by %s: %v", event.ID, k.source, reason),
+		Err:        reason,
+	})
+	if err != nil {
+		k.logger.Errorf("Unable to create '.error' event: %v", err)
+		return
+	}
+	if err := eventSender(*errorEvent); err != nil {
+		k.logger.Errorf("Unable to send '.error' event: %v", err)
+	}
+}
+
+func noOpHealthEndpointRunner(port string, cp *controlplane.ControlPlane, opts ...api.HealthHandlerOption) {
+}
 
-func newHealthEndpointRunner(port string, cp *controlplane.ControlPlane) {
+func newHealthEndpointRunner(port string, cp *controlplane.ControlPlane, opts ...api.HealthHandlerOption) {
 	go func() {
-		api.RunHealthEndpoint(port, api.WithReadinessConditionFunc(func() bool {
+		api.RunHealthEndpoint(port, append([]api.HealthHandlerOption{api.WithReadinessConditionFunc(func() bool {
 			return cp.IsRegistered()
-		}))
+		})}, opts...)...)
 	}()
 }
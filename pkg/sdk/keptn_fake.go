@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"github.com/keptn/go-utils/pkg/api/models"
 	api "github.com/keptn/go-utils/pkg/api/utils"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
 	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
 	"github.com/keptn/go-utils/pkg/sdk/connector/types"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"io/ioutil"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -19,6 +24,9 @@ type FakeKeptn struct {
 	TestResourceHandler ResourceHandler
 	SentEvents          []models.KeptnContextExtendedCE
 	Keptn               *Keptn
+	// IntegrationID is passed along with the events created via NewEvent, like the control plane does after the registration
+	IntegrationID string
+	mtx           sync.Mutex
 }
 
 func (f *FakeKeptn) GetResourceHandler() ResourceHandler {
@@ -31,6 +39,7 @@ func (f *FakeKeptn) GetResourceHandler() ResourceHandler {
 func (f *FakeKeptn) NewEvent(event models.KeptnContextExtendedCE) error {
 	ctx := context.WithValue(context.TODO(), types.EventSenderKey, controlplane.EventSender(f.fakeSender))
 	ctx = context.WithValue(ctx, gracefulShutdownKey, &nopWG{})
+	ctx = context.WithValue(ctx, types.IntegrationIDKey, f.IntegrationID)
 	return f.Keptn.OnEvent(ctx, event)
 }
 
@@ -82,10 +91,61 @@ func (f *FakeKeptn) SetAPI(api api.KeptnInterface) {
 	f.Keptn.api = api
 }
 
+func (f *FakeKeptn) SetAPIV2(api apiv2.KeptnInterface) {
+	f.Keptn.apiV2 = api
+}
+
 func (f *FakeKeptn) AddTaskEventHandler(eventType string, handler TaskHandler, options TaskHandlerOptions) {
 	f.Keptn.taskRegistry.Add(eventType, taskEntry{taskHandler: handler, eventFilters: options.Filters, taskHandlerOpts: options})
 }
 
+func (f *FakeKeptn) AddContextTaskEventHandler(eventType string, handler ContextTaskHandler, options TaskHandlerOptions) {
+	f.Keptn.taskRegistry.Add(eventType, taskEntry{contextTaskHandler: handler, eventFilters: options.Filters, taskHandlerOpts: options})
+}
+
+func (f *FakeKeptn) SetIdempotency(options IdempotencyOptions) {
+	WithIdempotency(options)(f.Keptn)
+}
+
+func (f *FakeKeptn) SetMeterProvider(meterProvider metric.MeterProvider) {
+	f.Keptn.meterProvider = meterProvider
+	f.Keptn.metrics = metrics.New(meterProvider)
+}
+
+func (f *FakeKeptn) SetTracerProvider(tracerProvider trace.TracerProvider) {
+	f.Keptn.tracerProvider = tracerProvider
+}
+
+func (f *FakeKeptn) SetOutbox(options OutboxOptions) {
+	WithOutbox(options)(f.Keptn)
+	f.Keptn.initOutbox()
+}
+
+func (f *FakeKeptn) SetPendingTasks(store PendingTaskStore) {
+	WithPendingTasks(store)(f.Keptn)
+}
+
+// SetSecretProviders replaces the providers secrets are looked up from, e.g. with a StaticSecretProvider
+func (f *FakeKeptn) SetSecretProviders(providers ...SecretProvider) {
+	f.Keptn.secretProviders = providers
+}
+
+func (f *FakeKeptn) SetLogForwarding(options LogForwardingOptions) {
+	WithLogForwarding(options)(f.Keptn)
+}
+
+func (f *FakeKeptn) SetSubscriptionMode(mode SubscriptionMode) {
+	WithSubscriptionMode(mode)(f.Keptn)
+}
+
+func (f *FakeKeptn) AddEventObserver(eventType string, observer EventObserver, filters ...func(keptnHandle IKeptn, event KeptnEvent) bool) {
+	WithEventObserver(eventType, observer, filters...)(f.Keptn)
+}
+
+func (f *FakeKeptn) AddTaskMiddleware(middlewares ...TaskMiddleware) {
+	f.Keptn.taskMiddlewares = append(f.Keptn.taskMiddlewares, middlewares...)
+}
+
 // AddTaskEventHandler registers a TaskHandler
 // Deprecated: use AddTaskEventHandler
 func (f *FakeKeptn) AddTaskHandler(eventType string, handler TaskHandler, filters ...func(keptnHandle IKeptn, event KeptnEvent) bool) {
@@ -97,6 +157,8 @@ func (f *FakeKeptn) AddTaskHandlerWithSubscriptionID(eventType string, handler T
 }
 
 func (f *FakeKeptn) fakeSender(ce models.KeptnContextExtendedCE) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
 	f.SentEvents = append(f.SentEvents, ce)
 	return nil
 }
@@ -110,13 +172,18 @@ func NewFakeKeptn(source string) *FakeKeptn {
 			api:                    panicKeptnInterface{},
 			resourceHandler:        resourceHandler,
 			taskRegistry:           newTaskMap(),
+			observerRegistry:       newObserverRegistry(),
+			workerPools:            newWorkerPools(),
+			runningTasks:           newRunningTasks(),
 			syncProcessing:         true,
 			automaticEventResponse: true,
 			gracefulShutdown:       false,
-			logger:                 newDefaultLogger(),
+			drainOptions:           DrainOptions{GracePeriod: defaultDrainGracePeriod},
 			healthEndpointRunner:   noOpHealthEndpointRunner,
+			secretRedactor:         newSecretRedactor(),
 		},
 	}
+	fakeKeptn.Keptn.logger = newRedactingLogger(newDefaultLogger(), fakeKeptn.Keptn.secretRedactor)
 	fakeKeptn.Keptn.eventSender = fakeKeptn.fakeSender
 	return fakeKeptn
 }
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"github.com/keptn/go-utils/pkg/sdk/connector/types"
 	"github.com/keptn/go-utils/pkg/sdk/internal/config"
 
 	"github.com/google/uuid"
@@ -79,6 +83,28 @@ func Test_SendEvents(t *testing.T) {
 		require.Equal(t, v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"}, sentEvent.Data)
 	})
 
+	t.Run("Send Status Changed Event", func(t *testing.T) {
+		var sentEvent models.KeptnContextExtendedCE
+		keptnSDK := NewKeptn("my-service")
+		keptnSDK.eventSender = func(ce models.KeptnContextExtendedCE) error {
+			sentEvent = ce
+			return nil
+		}
+		err := keptnSDK.SendStatusChangedEvent(KeptnEvent{
+			Contenttype:    "application/json",
+			ID:             "id",
+			Shkeptncontext: "context",
+			Source:         strutils.Stringp("source"),
+			Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+		}, v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc", Message: "step 1 of 2"})
+		require.NoError(t, err)
+		require.NotNil(t, sentEvent)
+		require.NotEmpty(t, sentEvent.ID)
+		require.Equal(t, "id", sentEvent.Triggeredid)
+		require.Equal(t, "sh.keptn.event.faketask.status.changed", *sentEvent.Type)
+		require.Equal(t, v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc", Message: "step 1 of 2"}, sentEvent.Data)
+	})
+
 	t.Run("Send Finished Event", func(t *testing.T) {
 		var sentEvent models.KeptnContextExtendedCE
 		keptnSDK := NewKeptn("my-service")
@@ -150,6 +176,186 @@ func Test_WhenReceivingAnEvent_StartedEventAndFinishedEventsAreSent(t *testing.T
 	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
 }
 
+func Test_WhenReceivingAnEvent_TaskHandlerSendsStatusChangedEvents(t *testing.T) {
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		for _, step := range []string{"step 1", "step 2"} {
+			if err := keptnHandle.SendStatusChangedEvent(event, v0_2_0.EventData{Message: step}); err != nil {
+				return nil, &Error{Err: err, StatusType: v0_2_0.StatusErrored, ResultType: v0_2_0.ResultFailed}
+			}
+		}
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+	})
+
+	fakeKeptn.AssertNumberOfEventSent(t, 4)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.status.changed")
+	fakeKeptn.AssertSentEventType(t, 2, "sh.keptn.event.faketask.status.changed")
+	fakeKeptn.AssertSentEventType(t, 3, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEvent(t, 2, func(ce models.KeptnContextExtendedCE) bool {
+		return ce.Triggeredid == "id" && ce.Data.(v0_2_0.EventData).Message == "step 2"
+	})
+}
+
+func Test_WhenReceivingAnEvent_ContextTaskHandlerIsExecuted(t *testing.T) {
+	var handlerCtx context.Context
+	taskHandler := &ContextTaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		handlerCtx = ctx
+		require.NoError(t, ctx.Err())
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddContextTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{})
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+	})
+
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
+	// the context is released as soon as the handler returned
+	require.ErrorIs(t, handlerCtx.Err(), context.Canceled)
+}
+
+func Test_WhenReceivingAnAbortSignal_ContextOfRunningTaskIsCancelled(t *testing.T) {
+	tests := []struct {
+		name        string
+		abortSignal models.KeptnContextExtendedCE
+		wantAborted bool
+	}{
+		{
+			name: "task invalidated",
+			abortSignal: models.KeptnContextExtendedCE{
+				Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+				ID:             "id-2",
+				Shkeptncontext: "context",
+				Source:         strutils.Stringp("shipyard-controller"),
+				Type:           strutils.Stringp("sh.keptn.event.faketask.invalidated"),
+			},
+			wantAborted: true,
+		},
+		{
+			name: "sequence aborted",
+			abortSignal: models.KeptnContextExtendedCE{
+				Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc", Status: v0_2_0.StatusAborted},
+				ID:             "id-2",
+				Shkeptncontext: "context",
+				Source:         strutils.Stringp("shipyard-controller"),
+				Type:           strutils.Stringp("sh.keptn.event.stg.delivery.finished"),
+			},
+			wantAborted: true,
+		},
+		{
+			name: "sequence finished",
+			abortSignal: models.KeptnContextExtendedCE{
+				Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc", Status: v0_2_0.StatusSucceeded},
+				ID:             "id-2",
+				Shkeptncontext: "context",
+				Source:         strutils.Stringp("shipyard-controller"),
+				Type:           strutils.Stringp("sh.keptn.event.stg.delivery.finished"),
+			},
+			wantAborted: false,
+		},
+		{
+			name: "other keptn context invalidated",
+			abortSignal: models.KeptnContextExtendedCE{
+				Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+				ID:             "id-2",
+				Shkeptncontext: "other-context",
+				Source:         strutils.Stringp("shipyard-controller"),
+				Type:           strutils.Stringp("sh.keptn.event.faketask.invalidated"),
+			},
+			wantAborted: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKeptn := NewFakeKeptn("fake")
+			taskHandler := &ContextTaskHandlerMock{}
+			taskHandler.ExecuteFunc = func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+				// the event is processed synchronously by the fake, so the abort signal arrives while the task is running
+				fakeKeptn.NewEvent(tt.abortSignal)
+				if tt.wantAborted {
+					require.ErrorIs(t, ctx.Err(), context.Canceled)
+					require.ErrorIs(t, context.Cause(ctx), ErrTaskAborted)
+				} else {
+					require.NoError(t, ctx.Err())
+				}
+				return FakeTaskData{}, nil
+			}
+			fakeKeptn.AddContextTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{})
+			fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+				Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+				ID:             "id",
+				Shkeptncontext: "context",
+				Source:         strutils.Stringp("source"),
+				Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+			})
+			fakeKeptn.AssertNumberOfEventSent(t, 2)
+		})
+	}
+}
+
+func Test_WhenReceivingAnAbortSignal_PatternHandlersAreNotExecuted(t *testing.T) {
+	executed := []string{}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		executed = append(executed, *event.Type)
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.*", taskHandler)
+	fakeKeptn.AddTaskHandler("sh.keptn.event.othertask.invalidated", taskHandler)
+
+	for _, eventType := range []string{"sh.keptn.event.faketask.invalidated", "sh.keptn.event.othertask.invalidated"} {
+		fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+			Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+			ID:             "id",
+			Shkeptncontext: "context",
+			Source:         strutils.Stringp("shipyard-controller"),
+			Type:           strutils.Stringp(eventType),
+		})
+	}
+
+	require.Equal(t, []string{"sh.keptn.event.othertask.invalidated"}, executed)
+}
+
+func Test_WhenShuttingDown_ContextOfRunningTaskIsNotCancelledBeforeTheDrainTimeout(t *testing.T) {
+	shutdownCtx, shutdown := context.WithCancel(context.Background())
+	taskHandler := &ContextTaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		shutdown()
+		require.NoError(t, ctx.Err())
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddContextTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{})
+	ctx := context.WithValue(shutdownCtx, types.EventSenderKey, controlplane.EventSender(fakeKeptn.fakeSender))
+	ctx = context.WithValue(ctx, gracefulShutdownKey, &nopWG{})
+	fakeKeptn.Keptn.OnEvent(ctx, models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+	})
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+}
+
 func Test_WhenReceivingAnEvent_AndAutomaticEventResponseIsGloballyDiabled_StartedEventAndFinishedEventsAreNotSent(t *testing.T) {
 	taskHandler := &TaskHandlerMock{}
 	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) { return FakeTaskData{}, nil }
@@ -236,6 +442,402 @@ func Test_WhenReceivingAnEvent_TaskHandlerFails(t *testing.T) {
 	fakeKeptn.AssertSentEventResult(t, 1, v0_2_0.ResultFailed)
 }
 
+func Test_WhenReceivingAnEvent_TaskHandlerTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		<-release
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{Timeout: 10 * time.Millisecond})
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+	})
+
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusErrored)
+	fakeKeptn.AssertSentEventResult(t, 1, v0_2_0.ResultFailed)
+	fakeKeptn.AssertSentEvent(t, 1, func(ce models.KeptnContextExtendedCE) bool {
+		return ce.Data.(v0_2_0.EventData).Message == "task handler for event sh.keptn.event.faketask.triggered did not finish within 10ms"
+	})
+}
+
+func Test_WhenTaskHandlerTimedOut_EventsSentByHandlerAreDropped(t *testing.T) {
+	release := make(chan struct{})
+	lateSendErr := make(chan error, 1)
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		<-release
+		lateSendErr <- keptnHandle.SendFinishedEvent(event, FakeTaskData{})
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{Timeout: 10 * time.Millisecond})
+	fakeKeptn.NewEvent(newTestTaskTriggeredEvent())
+
+	close(release)
+
+	require.Error(t, <-lateSendErr)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusErrored)
+}
+
+func Test_WhenReceivingAnEvent_ContextTaskHandlerFinishesWithinTimeout(t *testing.T) {
+	taskHandler := &ContextTaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		_, hasDeadline := ctx.Deadline()
+		require.True(t, hasDeadline)
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddContextTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{Timeout: time.Minute})
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+	})
+
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusSucceeded)
+}
+
+func Test_WhenReceivingAnEvent_TaskHandlerPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TaskHandlerOptions
+	}{
+		{
+			name:    "without timeout",
+			options: TaskHandlerOptions{},
+		},
+		{
+			name:    "with timeout",
+			options: TaskHandlerOptions{Timeout: time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskHandler := &TaskHandlerMock{}
+			taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+				panic("boom")
+			}
+			fakeKeptn := NewFakeKeptn("fake")
+			fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, tt.options)
+			require.NotPanics(t, func() {
+				fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+					Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+					ID:             "id",
+					Shkeptncontext: "context",
+					Source:         strutils.Stringp("source"),
+					Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+				})
+			})
+
+			fakeKeptn.AssertNumberOfEventSent(t, 3)
+			fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+			fakeKeptn.AssertSentEventType(t, 1, v0_2_0.ErrorLogEventName)
+			fakeKeptn.AssertSentEvent(t, 1, func(ce models.KeptnContextExtendedCE) bool {
+				return ce.Triggeredid == "id" && ce.Shkeptncontext == "context" &&
+					ce.Data.(v0_2_0.ErrorLogEvent) == v0_2_0.ErrorLogEvent{Message: "task handler panicked: boom", Task: "faketask"}
+			})
+			fakeKeptn.AssertSentEventType(t, 2, "sh.keptn.event.faketask.finished")
+			fakeKeptn.AssertSentEventStatus(t, 2, v0_2_0.StatusErrored)
+			fakeKeptn.AssertSentEventResult(t, 2, v0_2_0.ResultFailed)
+		})
+	}
+}
+
+func Test_WhenReceivingAnEvent_FilterPanics(t *testing.T) {
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) { return FakeTaskData{}, nil }
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler, func(keptnHandle IKeptn, event KeptnEvent) bool { panic("boom") })
+	require.NotPanics(t, func() {
+		fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+			Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+			ID:             "id",
+			Shkeptncontext: "context",
+			Source:         strutils.Stringp("source"),
+			Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+		})
+	})
+
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 0, v0_2_0.ErrorLogEventName)
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusErrored)
+}
+
+func Test_WhenReceivingAnEvent_FilterPanics_AndHandlerSkipsAutomaticResponse(t *testing.T) {
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) { return FakeTaskData{}, nil }
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{
+		SkipAutomaticResponse: true,
+		Filters:               []func(IKeptn, KeptnEvent) bool{func(keptnHandle IKeptn, event KeptnEvent) bool { panic("boom") }},
+	})
+	require.NotPanics(t, func() {
+		fakeKeptn.NewEvent(newTestTaskTriggeredEvent())
+	})
+
+	fakeKeptn.AssertNumberOfEventSent(t, 1)
+	fakeKeptn.AssertSentEventType(t, 0, v0_2_0.ErrorLogEventName)
+}
+
+func Test_WhenWorkerPoolIsSaturated_EventIsRejected(t *testing.T) {
+	release := make(chan struct{})
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		<-release
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.syncProcessing = false
+	WithWorkerPool(WorkerPoolOptions{MaxWorkers: 1, QueueSize: 1, SaturationPolicy: SaturationPolicyReject})(fakeKeptn.Keptn)
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{SkipAutomaticResponse: true})
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask2.triggered", taskHandler, TaskHandlerOptions{})
+
+	newEvent := func(id string, eventType string) models.KeptnContextExtendedCE {
+		return models.KeptnContextExtendedCE{
+			Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+			ID:             id,
+			Shkeptncontext: "context",
+			Source:         strutils.Stringp("source"),
+			Type:           strutils.Stringp(eventType),
+		}
+	}
+	fakeKeptn.NewEvent(newEvent("id-1", "sh.keptn.event.faketask.triggered"))
+	fakeKeptn.NewEvent(newEvent("id-2", "sh.keptn.event.faketask.triggered"))
+	require.Eventually(t, func() bool {
+		return *fakeKeptn.Keptn.WorkerPoolStatus().Global == WorkerPoolStatus{Running: 1, Queued: 1, MaxWorkers: 1, QueueSize: 1}
+	}, time.Second, 10*time.Millisecond)
+
+	fakeKeptn.NewEvent(newEvent("id-3", "sh.keptn.event.faketask2.triggered"))
+	fakeKeptn.AssertNumberOfEventSent(t, 1)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask2.finished")
+	fakeKeptn.AssertSentEventStatus(t, 0, v0_2_0.StatusErrored)
+	fakeKeptn.AssertSentEventResult(t, 0, v0_2_0.ResultFailed)
+	require.Equal(t, "id-3", fakeKeptn.SentEvents[0].Triggeredid)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return *fakeKeptn.Keptn.WorkerPoolStatus().Global == WorkerPoolStatus{Running: 0, Queued: 0, MaxWorkers: 1, QueueSize: 1}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_WhenEventTypeWorkerPoolIsSaturated_EventProcessingBlocks(t *testing.T) {
+	release := make(chan struct{})
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		<-release
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.syncProcessing = false
+	WithEventTypeWorkerPool("sh.keptn.event.faketask.triggered", WorkerPoolOptions{MaxWorkers: 1})(fakeKeptn.Keptn)
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{SkipAutomaticResponse: true})
+
+	event := models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+	}
+	fakeKeptn.NewEvent(event)
+
+	secondEventAccepted := make(chan struct{})
+	go func() {
+		fakeKeptn.NewEvent(event)
+		close(secondEventAccepted)
+	}()
+	require.Never(t, func() bool {
+		select {
+		case <-secondEventAccepted:
+			return true
+		default:
+			return false
+		}
+	}, 100*time.Millisecond, 10*time.Millisecond)
+	status := fakeKeptn.Keptn.WorkerPoolStatus()
+	require.Nil(t, status.Global)
+	require.Equal(t, WorkerPoolStatus{Running: 1, Queued: 0, MaxWorkers: 1, QueueSize: 0}, status.EventTypes["sh.keptn.event.faketask.triggered"])
+
+	close(release)
+	<-secondEventAccepted
+}
+
+func Test_WhenSequenceIsAborted_EventWaitingForWorkerIsDropped(t *testing.T) {
+	release := make(chan struct{})
+	mtx := sync.Mutex{}
+	executed := []string{}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		mtx.Lock()
+		executed = append(executed, event.ID)
+		mtx.Unlock()
+		<-release
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.syncProcessing = false
+	WithWorkerPool(WorkerPoolOptions{MaxWorkers: 1, QueueSize: 1})(fakeKeptn.Keptn)
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", taskHandler, TaskHandlerOptions{SkipAutomaticResponse: true})
+
+	running := newTestEvent("sh.keptn.event.faketask.triggered")
+	running.ID = "id-1"
+	running.Shkeptncontext = "context-1"
+	queued := newTestEvent("sh.keptn.event.faketask.triggered")
+	queued.ID = "id-2"
+	queued.Shkeptncontext = "context-2"
+	require.NoError(t, fakeKeptn.NewEvent(running))
+	require.Eventually(t, func() bool {
+		return fakeKeptn.Keptn.WorkerPoolStatus().Global.Running == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, fakeKeptn.NewEvent(queued))
+	require.Eventually(t, func() bool {
+		return *fakeKeptn.Keptn.WorkerPoolStatus().Global == WorkerPoolStatus{Running: 1, Queued: 1, MaxWorkers: 1, QueueSize: 1}
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, fakeKeptn.Keptn.InFlightTasks(), 2)
+
+	invalidated := newTestEvent("sh.keptn.event.faketask.invalidated")
+	invalidated.ID = "id-3"
+	invalidated.Shkeptncontext = "context-2"
+	require.NoError(t, fakeKeptn.NewEvent(invalidated))
+	require.Eventually(t, func() bool {
+		return *fakeKeptn.Keptn.WorkerPoolStatus().Global == WorkerPoolStatus{Running: 1, Queued: 0, MaxWorkers: 1, QueueSize: 1}
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return len(fakeKeptn.Keptn.InFlightTasks()) == 0
+	}, time.Second, 10*time.Millisecond)
+	mtx.Lock()
+	defer mtx.Unlock()
+	require.Equal(t, []string{"id-1"}, executed)
+}
+
+func Test_WhenHandlerIsRegisteredWithPattern_WorkerPoolOfPatternApplies(t *testing.T) {
+	release := make(chan struct{})
+	mtx := sync.Mutex{}
+	executed := []string{}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		mtx.Lock()
+		executed = append(executed, *event.Type)
+		mtx.Unlock()
+		<-release
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.syncProcessing = false
+	WithEventTypeWorkerPool("sh.keptn.event.*.triggered", WorkerPoolOptions{MaxWorkers: 1, SaturationPolicy: SaturationPolicyReject})(fakeKeptn.Keptn)
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.*.triggered", taskHandler, TaskHandlerOptions{SkipAutomaticResponse: true})
+
+	require.NoError(t, fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered")))
+	require.Eventually(t, func() bool {
+		return fakeKeptn.Keptn.WorkerPoolStatus().EventTypes["sh.keptn.event.*.triggered"].Running == 1
+	}, time.Second, 10*time.Millisecond)
+	// the handler is busy with the first event, so that the event of another type matching the pattern is rejected
+	require.NoError(t, fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.othertask.triggered")))
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return fakeKeptn.Keptn.WorkerPoolStatus().EventTypes["sh.keptn.event.*.triggered"].Running == 0
+	}, time.Second, 10*time.Millisecond)
+	mtx.Lock()
+	defer mtx.Unlock()
+	require.Equal(t, []string{"sh.keptn.event.faketask.triggered"}, executed)
+}
+
+func Test_WhenReceivingAnEvent_TypedTaskHandlerReceivesDecodedData(t *testing.T) {
+	var receivedData v0_2_0.DeploymentTriggeredEventData
+	handler := TypedTaskHandlerFunc[v0_2_0.DeploymentTriggeredEventData, v0_2_0.DeploymentFinishedEventData](
+		func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent, data v0_2_0.DeploymentTriggeredEventData) (*v0_2_0.DeploymentFinishedEventData, *Error) {
+			receivedData = data
+			return &v0_2_0.DeploymentFinishedEventData{
+				EventData:  data.EventData,
+				Deployment: v0_2_0.DeploymentFinishedData{DeploymentURIsLocal: data.Deployment.DeploymentURIsLocal},
+			}, nil
+		})
+	fakeKeptn := NewFakeKeptn("fake")
+	WithTypedTaskHandler[v0_2_0.DeploymentTriggeredEventData, v0_2_0.DeploymentFinishedEventData]("sh.keptn.event.deployment.triggered", handler)(fakeKeptn.Keptn)
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data: v0_2_0.DeploymentTriggeredEventData{
+			EventData:  v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+			Deployment: v0_2_0.DeploymentTriggeredData{DeploymentURIsLocal: []string{"http://svc:8080"}},
+		},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.deployment.triggered"),
+	})
+
+	require.Equal(t, "prj", receivedData.Project)
+	require.Equal(t, []string{"http://svc:8080"}, receivedData.Deployment.DeploymentURIsLocal)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.deployment.started")
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.deployment.finished")
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusSucceeded)
+	finishedData := v0_2_0.DeploymentFinishedEventData{}
+	require.NoError(t, v0_2_0.EventDataAs(fakeKeptn.SentEvents[1], &finishedData))
+	require.Equal(t, []string{"http://svc:8080"}, finishedData.Deployment.DeploymentURIsLocal)
+}
+
+func Test_WhenReceivingAnEvent_TypedTaskHandlerReturnsNoResult(t *testing.T) {
+	handler := TypedTaskHandlerFunc[v0_2_0.EventData, v0_2_0.EventData](
+		func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent, data v0_2_0.EventData) (*v0_2_0.EventData, *Error) {
+			return nil, nil
+		})
+	fakeKeptn := NewFakeKeptn("fake")
+	WithTypedTaskHandler[v0_2_0.EventData, v0_2_0.EventData]("sh.keptn.event.faketask.triggered", handler)(fakeKeptn.Keptn)
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+	})
+
+	fakeKeptn.AssertNumberOfEventSent(t, 1)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+}
+
+func Test_WhenReceivingAnEvent_TypedTaskHandlerCannotDecodeData(t *testing.T) {
+	executed := false
+	handler := TypedTaskHandlerFunc[v0_2_0.DeploymentTriggeredEventData, v0_2_0.DeploymentFinishedEventData](
+		func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent, data v0_2_0.DeploymentTriggeredEventData) (*v0_2_0.DeploymentFinishedEventData, *Error) {
+			executed = true
+			return &v0_2_0.DeploymentFinishedEventData{}, nil
+		})
+	fakeKeptn := NewFakeKeptn("fake")
+	WithTypedTaskHandler[v0_2_0.DeploymentTriggeredEventData, v0_2_0.DeploymentFinishedEventData]("sh.keptn.event.deployment.triggered", handler)(fakeKeptn.Keptn)
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data:           map[string]interface{}{"project": "prj", "stage": "stg", "service": "svc", "deployment": "not-an-object"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.deployment.triggered"),
+	})
+
+	require.False(t, executed)
+	fakeKeptn.AssertNumberOfEventSent(t, 1)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.deployment.finished")
+	fakeKeptn.AssertSentEventStatus(t, 0, v0_2_0.StatusErrored)
+	fakeKeptn.AssertSentEventResult(t, 0, v0_2_0.ResultFailed)
+}
+
 func Test_WhenReceivingBadEvent_NoEventIsSent(t *testing.T) {
 	taskHandler := &TaskHandlerMock{}
 	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) { return FakeTaskData{}, nil }
@@ -307,6 +909,52 @@ func Test_InitialRegistrationData_EmptyPubSubTopics(t *testing.T) {
 	require.Equal(t, 0, len(regData.Subscriptions))
 }
 
+func Test_InitialRegistrationData_PubSubTopicPatternsAreNotTranslated(t *testing.T) {
+	keptn := Keptn{env: config.EnvConfig{PubSubTopic: "sh.keptn.event.*.triggered,sh.keptn.event.deployment.*"}}
+	regData := keptn.RegistrationData()
+	require.Equal(t, []models.EventSubscription{{Event: "sh.keptn.event.*.triggered"}, {Event: "sh.keptn.event.deployment.*"}}, regData.Subscriptions)
+}
+
+func Test_WhenReceivingAnEvent_HandlerRegisteredForMatchingPatternIsExecuted(t *testing.T) {
+	executed := []string{}
+	newHandler := func(name string) *TaskHandlerMock {
+		return &TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+			executed = append(executed, name)
+			return FakeTaskData{}, nil
+		}}
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.*.triggered", newHandler("all"), TaskHandlerOptions{})
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.deployment.*", newHandler("deployment"), TaskHandlerOptions{SkipAutomaticResponse: true})
+
+	for _, eventType := range []string{"sh.keptn.event.test.triggered", "sh.keptn.event.deployment.triggered"} {
+		fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+			Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+			ID:             "id",
+			Shkeptncontext: "context",
+			Source:         strutils.Stringp("source"),
+			Type:           strutils.Stringp(eventType),
+		})
+	}
+
+	require.Equal(t, []string{"all", "deployment"}, executed)
+	// the options of the matching handler apply, i.e. no automatic response for the deployment handler
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.test.started")
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.test.finished")
+}
+
+// newTestEvent creates an event of the given type for the service svc in stage stg of project prj
+func newTestEvent(eventType string) models.KeptnContextExtendedCE {
+	return models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp(eventType),
+	}
+}
+
 func newTestTaskTriggeredEvent() models.KeptnContextExtendedCE {
 	return models.KeptnContextExtendedCE{
 		Contenttype:    "application/json",
@@ -346,3 +994,15 @@ func (mock *TaskHandlerMock) Execute(keptnHandle IKeptn, event KeptnEvent) (inte
 	}
 	return mock.ExecuteFunc(keptnHandle, event)
 }
+
+type ContextTaskHandlerMock struct {
+	// ExecuteFunc mocks the Execute method.
+	ExecuteFunc func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error)
+}
+
+func (mock *ContextTaskHandlerMock) Execute(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+	if mock.ExecuteFunc == nil {
+		panic("ContextTaskHandlerMock.ExecuteFunc: method is nil but taskHandler.Execute was just called")
+	}
+	return mock.ExecuteFunc(ctx, keptnHandle, event)
+}
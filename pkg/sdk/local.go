@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	localeventsource "github.com/keptn/go-utils/pkg/sdk/connector/eventsource/local"
+	"github.com/keptn/go-utils/pkg/sdk/connector/subscriptionsource"
+	sdk "github.com/keptn/go-utils/pkg/sdk/internal/api"
+)
+
+// LocalOptions configures where the sdk reads events from and writes events to when running locally
+type LocalOptions struct {
+	// EventsDirectory is a directory containing JSON files with the events to process
+	EventsDirectory string
+	// Input is a reader providing a stream of JSON encoded events, e.g. os.Stdin
+	Input io.Reader
+	// HTTPAddress is the address of a local HTTP server receiving CloudEvents, e.g. localhost:8090
+	HTTPAddress string
+	// Output is the writer the sent events are written to as JSON. Per default, os.Stdout is used
+	Output io.Writer
+	// OutputDirectory is a directory every sent event is written to as JSON file, instead of Output
+	OutputDirectory string
+	// ExitWhenDone determines whether Start returns as soon as all events of EventsDirectory and Input have been processed.
+	// It has no effect if HTTPAddress is set
+	ExitWhenDone bool
+	// HealthEndpoint determines whether the health endpoint configured in the Config is started.
+	// Per default, no health endpoint is started when running locally
+	HealthEndpoint bool
+}
+
+// NewLocalKeptn creates a new Keptn running without a Keptn control plane, e.g. to develop an integration locally.
+// Instead of NATS or the Keptn API, events are read from the sources configured in the given LocalOptions and
+// dispatched to the registered task handlers and event observers. Events sent by the sdk are written to the
+// configured output. The subscriptions are derived from the registered task handlers and observers, as well as
+// from the PUBSUB_TOPIC of the given configuration.
+// The Keptn API is not used, unless an API set is provided via WithAPISet or WithAPISetV2. Calls to the API report
+// ErrNotAvailableLocally then and secrets are only looked up from files and environment variables per default
+func NewLocalKeptn(source string, cfg Config, local LocalOptions, opts ...KeptnOption) (*Keptn, error) {
+	keptn := newKeptn(source, cfg, opts...)
+	keptn.env.HealthEndpointEnabled = keptn.env.HealthEndpointEnabled && local.HealthEndpoint
+	if len(keptn.secretOptions.Precedence) == 0 {
+		keptn.secretOptions.Precedence = []SecretSource{SecretSourceFiles, SecretSourceEnv}
+	}
+	if keptn.httpClient == nil {
+		keptn.httpClient = &http.Client{}
+	}
+	// API sets provided via options take precedence, since they are applied afterwards
+	keptn.initializationOpts = append([]sdk.InitializationOption{
+		sdk.WithKeptnAPI(localKeptnInterface{}),
+		sdk.WithKeptnAPIV2(localKeptnInterfaceV2{}),
+	}, keptn.initializationOpts...)
+
+	eventSourceOpts := []func(*localeventsource.LocalEventSource){localeventsource.WithLogger(keptn.logger)}
+	if local.EventsDirectory != "" {
+		eventSourceOpts = append(eventSourceOpts, localeventsource.WithDirectory(local.EventsDirectory))
+	}
+	if local.Input != nil {
+		eventSourceOpts = append(eventSourceOpts, localeventsource.WithReader(local.Input))
+	}
+	if local.HTTPAddress != "" {
+		eventSourceOpts = append(eventSourceOpts, localeventsource.WithHTTPReceiver(local.HTTPAddress))
+	}
+	if local.Output != nil {
+		eventSourceOpts = append(eventSourceOpts, localeventsource.WithOutput(local.Output))
+	}
+	if local.OutputDirectory != "" {
+		eventSourceOpts = append(eventSourceOpts, localeventsource.WithOutputDirectory(local.OutputDirectory))
+	}
+	if local.ExitWhenDone {
+		eventSourceOpts = append(eventSourceOpts, localeventsource.WithExitWhenDone())
+	}
+	eventSource := localeventsource.New(eventSourceOpts...)
+	subscriptionSource := subscriptionsource.NewFixedSubscriptionSource(
+		subscriptionsource.WithFixedSubscriptions(keptn.subscriptionsFor(SubscriptionModeMerged)...),
+	)
+	keptn.initializationOpts = append(keptn.initializationOpts, sdk.WithControlPlaneComponents(subscriptionSource, eventSource, nil))
+
+	if err := keptn.initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize keptn sdk: %w", err)
+	}
+	return keptn, nil
+}
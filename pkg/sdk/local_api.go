@@ -0,0 +1,638 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+)
+
+// ErrNotAvailableLocally is returned by the API sets of a Keptn created by NewLocalKeptn, unless API sets have been
+// provided via WithAPISet or WithAPISetV2. Methods reporting a *models.Error return one with code 501 instead
+var ErrNotAvailableLocally = errors.New("not available when running without a Keptn control plane")
+
+// notAvailableLocally returns the *models.Error reported by the methods of the local API sets
+func notAvailableLocally() *models.Error {
+	message := ErrNotAvailableLocally.Error()
+	return &models.Error{Code: http.StatusNotImplemented, Message: &message}
+}
+
+// localKeptnInterface is the API set used by NewLocalKeptn, unless one has been provided via WithAPISet.
+// Its APIs report ErrNotAvailableLocally, forwarded logs are dropped
+type localKeptnInterface struct {
+}
+
+func (l localKeptnInterface) AuthV1() api.AuthV1Interface {
+	return localAuthV1API{}
+}
+
+func (l localKeptnInterface) EventsV1() api.EventsV1Interface {
+	return localEventsV1API{}
+}
+
+func (l localKeptnInterface) LogsV1() api.LogsV1Interface {
+	return localLogsV1API{}
+}
+
+func (l localKeptnInterface) ProjectsV1() api.ProjectsV1Interface {
+	return localProjectsV1API{}
+}
+
+func (l localKeptnInterface) ResourcesV1() api.ResourcesV1Interface {
+	return localResourcesV1API{}
+}
+
+func (l localKeptnInterface) SecretsV1() api.SecretsV1Interface {
+	return localSecretsV1API{}
+}
+
+func (l localKeptnInterface) SequencesV1() api.SequencesV1Interface {
+	return localSequencesV1API{}
+}
+
+func (l localKeptnInterface) ServicesV1() api.ServicesV1Interface {
+	return localServicesV1API{}
+}
+
+func (l localKeptnInterface) StagesV1() api.StagesV1Interface {
+	return localStagesV1API{}
+}
+
+func (l localKeptnInterface) UniformV1() api.UniformV1Interface {
+	return localUniformV1API{}
+}
+
+func (l localKeptnInterface) ShipyardControlV1() api.ShipyardControlV1Interface {
+	return localShipyardControlV1API{}
+}
+
+func (l localKeptnInterface) APIV1() api.APIV1Interface {
+	return localAPIV1{}
+}
+
+// localKeptnInterfaceV2 is the v2 API set used by NewLocalKeptn, unless one has been provided via WithAPISetV2.
+// Its APIs report ErrNotAvailableLocally, forwarded logs are dropped
+type localKeptnInterfaceV2 struct {
+}
+
+func (l localKeptnInterfaceV2) API() apiv2.APIInterface {
+	return localAPI{}
+}
+
+func (l localKeptnInterfaceV2) Auth() apiv2.AuthInterface {
+	return localAuthAPI{}
+}
+
+func (l localKeptnInterfaceV2) Events() apiv2.EventsInterface {
+	return localEventsAPI{}
+}
+
+func (l localKeptnInterfaceV2) Logs() apiv2.LogsInterface {
+	return localLogsAPI{}
+}
+
+func (l localKeptnInterfaceV2) Projects() apiv2.ProjectsInterface {
+	return localProjectsAPI{}
+}
+
+func (l localKeptnInterfaceV2) Secrets() apiv2.SecretsInterface {
+	return localSecretsAPI{}
+}
+
+func (l localKeptnInterfaceV2) Sequences() apiv2.SequencesInterface {
+	return localSequencesAPI{}
+}
+
+func (l localKeptnInterfaceV2) Services() apiv2.ServicesInterface {
+	return localServicesAPI{}
+}
+
+func (l localKeptnInterfaceV2) Stages() apiv2.StagesInterface {
+	return localStagesAPI{}
+}
+
+func (l localKeptnInterfaceV2) Uniform() apiv2.UniformInterface {
+	return localUniformAPI{}
+}
+
+func (l localKeptnInterfaceV2) ShipyardControl() apiv2.ShipyardControlInterface {
+	return localShipyardControlAPI{}
+}
+
+func (l localKeptnInterfaceV2) Resources() apiv2.ResourcesInterface {
+	return localResourcesAPI{}
+}
+
+// localAuthV1API is the AuthV1 API of localKeptnInterface
+type localAuthV1API struct {
+}
+
+func (l localAuthV1API) Authenticate() (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+// localEventsV1API is the EventsV1 API of localKeptnInterface
+type localEventsV1API struct {
+}
+
+func (l localEventsV1API) GetEvents(filter *api.EventFilter) ([]*models.KeptnContextExtendedCE, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localEventsV1API) GetEventsWithRetry(filter *api.EventFilter, maxRetries int, retrySleepTime time.Duration) ([]*models.KeptnContextExtendedCE, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localLogsV1API is the LogsV1 API of localKeptnInterface
+type localLogsV1API struct {
+}
+
+// Log drops the given entries, since there is no control plane to forward them to
+func (l localLogsV1API) Log(logs []models.LogEntry) {
+}
+
+func (l localLogsV1API) Flush() error {
+	return nil
+}
+
+func (l localLogsV1API) GetLogs(params models.GetLogsParams) (*models.GetLogsResponse, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localLogsV1API) DeleteLogs(filter models.LogFilter) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localLogsV1API) Start(ctx context.Context) {
+}
+
+// localProjectsV1API is the ProjectsV1 API of localKeptnInterface
+type localProjectsV1API struct {
+}
+
+func (l localProjectsV1API) CreateProject(project models.Project) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localProjectsV1API) DeleteProject(project models.Project) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localProjectsV1API) GetProject(project models.Project) (*models.Project, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localProjectsV1API) GetAllProjects() ([]*models.Project, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localProjectsV1API) UpdateConfigurationServiceProject(project models.Project) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+// localResourcesV1API is the ResourcesV1 API of localKeptnInterface
+type localResourcesV1API struct {
+}
+
+func (l localResourcesV1API) CreateResources(project string, stage string, service string, resources []*models.Resource) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localResourcesV1API) CreateProjectResources(project string, resources []*models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) GetProjectResource(project string, resourceURI string) (*models.Resource, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) UpdateProjectResource(project string, resource *models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) DeleteProjectResource(project string, resourceURI string) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) UpdateProjectResources(project string, resources []*models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) CreateStageResources(project string, stage string, resources []*models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) GetStageResource(project string, stage string, resourceURI string) (*models.Resource, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) UpdateStageResource(project string, stage string, resource *models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) UpdateStageResources(project string, stage string, resources []*models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) DeleteStageResource(project string, stage string, resourceURI string) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) CreateServiceResources(project string, stage string, service string, resources []*models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) GetServiceResource(project string, stage string, service string, resourceURI string) (*models.Resource, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) UpdateServiceResource(project string, stage string, service string, resource *models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) UpdateServiceResources(project string, stage string, service string, resources []*models.Resource) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) DeleteServiceResource(project string, stage string, service string, resourceURI string) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) GetAllStageResources(project string, stage string) ([]*models.Resource, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localResourcesV1API) GetAllServiceResources(project string, stage string, service string) ([]*models.Resource, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localSecretsV1API is the SecretsV1 API of localKeptnInterface
+type localSecretsV1API struct {
+}
+
+func (l localSecretsV1API) CreateSecret(secret models.Secret) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localSecretsV1API) UpdateSecret(secret models.Secret) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localSecretsV1API) DeleteSecret(secretName, secretScope string) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localSecretsV1API) GetSecrets() (*models.GetSecretsResponse, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localSequencesV1API is the SequencesV1 API of localKeptnInterface
+type localSequencesV1API struct {
+}
+
+func (l localSequencesV1API) ControlSequence(params api.SequenceControlParams) error {
+	return ErrNotAvailableLocally
+}
+
+// localServicesV1API is the ServicesV1 API of localKeptnInterface
+type localServicesV1API struct {
+}
+
+func (l localServicesV1API) CreateServiceInStage(project string, stage string, serviceName string) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localServicesV1API) DeleteServiceFromStage(project string, stage string, serviceName string) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localServicesV1API) GetService(project, stage, service string) (*models.Service, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localServicesV1API) GetAllServices(project string, stage string) ([]*models.Service, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localStagesV1API is the StagesV1 API of localKeptnInterface
+type localStagesV1API struct {
+}
+
+func (l localStagesV1API) CreateStage(project string, stageName string) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localStagesV1API) GetAllStages(project string) ([]*models.Stage, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localUniformV1API is the UniformV1 API of localKeptnInterface
+type localUniformV1API struct {
+}
+
+func (l localUniformV1API) Ping(integrationID string) (*models.Integration, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localUniformV1API) RegisterIntegration(integration models.Integration) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localUniformV1API) CreateSubscription(integrationID string, subscription models.EventSubscription) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localUniformV1API) UnregisterIntegration(integrationID string) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localUniformV1API) GetRegistrations() ([]*models.Integration, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localShipyardControlV1API is the ShipyardControlV1 API of localKeptnInterface
+type localShipyardControlV1API struct {
+}
+
+func (l localShipyardControlV1API) GetOpenTriggeredEvents(filter api.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localAPIV1 is the APIV1 API of localKeptnInterface
+type localAPIV1 struct {
+}
+
+func (l localAPIV1) SendEvent(event models.KeptnContextExtendedCE) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localAPIV1) TriggerEvaluation(project string, stage string, service string, evaluation models.Evaluation) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localAPIV1) CreateProject(project models.CreateProject) (string, *models.Error) {
+	return "", notAvailableLocally()
+}
+
+func (l localAPIV1) UpdateProject(project models.CreateProject) (string, *models.Error) {
+	return "", notAvailableLocally()
+}
+
+func (l localAPIV1) DeleteProject(project models.Project) (*models.DeleteProjectResponse, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localAPIV1) CreateService(project string, service models.CreateService) (string, *models.Error) {
+	return "", notAvailableLocally()
+}
+
+func (l localAPIV1) DeleteService(project string, service string) (*models.DeleteServiceResponse, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localAPIV1) GetMetadata() (*models.Metadata, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+// localAPI is the API API of localKeptnInterfaceV2
+type localAPI struct {
+}
+
+func (l localAPI) SendEvent(ctx context.Context, event models.KeptnContextExtendedCE, opts apiv2.APISendEventOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localAPI) TriggerEvaluation(ctx context.Context, project string, stage string, service string, evaluation models.Evaluation, opts apiv2.APITriggerEvaluationOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localAPI) CreateProject(ctx context.Context, project models.CreateProject, opts apiv2.APICreateProjectOptions) (string, *models.Error) {
+	return "", notAvailableLocally()
+}
+
+func (l localAPI) UpdateProject(ctx context.Context, project models.CreateProject, opts apiv2.APIUpdateProjectOptions) (string, *models.Error) {
+	return "", notAvailableLocally()
+}
+
+func (l localAPI) DeleteProject(ctx context.Context, project models.Project, opts apiv2.APIDeleteProjectOptions) (*models.DeleteProjectResponse, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localAPI) CreateService(ctx context.Context, project string, service models.CreateService, opts apiv2.APICreateServiceOptions) (string, *models.Error) {
+	return "", notAvailableLocally()
+}
+
+func (l localAPI) DeleteService(ctx context.Context, project string, service string, opts apiv2.APIDeleteServiceOptions) (*models.DeleteServiceResponse, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localAPI) GetMetadata(ctx context.Context, opts apiv2.APIGetMetadataOptions) (*models.Metadata, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+// localAuthAPI is the Auth API of localKeptnInterfaceV2
+type localAuthAPI struct {
+}
+
+func (l localAuthAPI) Authenticate(ctx context.Context, opts apiv2.AuthAuthenticateOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+// localEventsAPI is the Events API of localKeptnInterfaceV2
+type localEventsAPI struct {
+}
+
+func (l localEventsAPI) GetEvents(ctx context.Context, filter *apiv2.EventFilter, opts apiv2.EventsGetEventsOptions) ([]*models.KeptnContextExtendedCE, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localEventsAPI) GetEventsWithRetry(ctx context.Context, filter *apiv2.EventFilter, maxRetries int, retrySleepTime time.Duration, opts apiv2.EventsGetEventsWithRetryOptions) ([]*models.KeptnContextExtendedCE, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localLogsAPI is the Logs API of localKeptnInterfaceV2
+type localLogsAPI struct {
+}
+
+// Log drops the given entries, since there is no control plane to forward them to
+func (l localLogsAPI) Log(logs []models.LogEntry, opts apiv2.LogsLogOptions) {
+}
+
+func (l localLogsAPI) Flush(ctx context.Context, opts apiv2.LogsFlushOptions) error {
+	return nil
+}
+
+func (l localLogsAPI) GetLogs(ctx context.Context, params models.GetLogsParams, opts apiv2.LogsGetLogsOptions) (*models.GetLogsResponse, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localLogsAPI) DeleteLogs(ctx context.Context, filter models.LogFilter, opts apiv2.LogsDeleteLogsOptions) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localLogsAPI) Start(ctx context.Context, opts apiv2.LogsStartOptions) {
+}
+
+// localProjectsAPI is the Projects API of localKeptnInterfaceV2
+type localProjectsAPI struct {
+}
+
+func (l localProjectsAPI) CreateProject(ctx context.Context, project models.Project, opts apiv2.ProjectsCreateProjectOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localProjectsAPI) DeleteProject(ctx context.Context, project models.Project, opts apiv2.ProjectsDeleteProjectOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localProjectsAPI) GetProject(ctx context.Context, project models.Project, opts apiv2.ProjectsGetProjectOptions) (*models.Project, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localProjectsAPI) GetAllProjects(ctx context.Context, opts apiv2.ProjectsGetAllProjectsOptions) ([]*models.Project, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localProjectsAPI) UpdateConfigurationServiceProject(ctx context.Context, project models.Project, opts apiv2.ProjectsUpdateConfigurationServiceProjectOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+// localSecretsAPI is the Secrets API of localKeptnInterfaceV2
+type localSecretsAPI struct {
+}
+
+func (l localSecretsAPI) CreateSecret(ctx context.Context, secret models.Secret, opts apiv2.SecretsCreateSecretOptions) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localSecretsAPI) UpdateSecret(ctx context.Context, secret models.Secret, opts apiv2.SecretsUpdateSecretOptions) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localSecretsAPI) DeleteSecret(ctx context.Context, secretName, secretScope string, opts apiv2.SecretsDeleteSecretOptions) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localSecretsAPI) GetSecrets(ctx context.Context, opts apiv2.SecretsGetSecretsOptions) (*models.GetSecretsResponse, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localSequencesAPI is the Sequences API of localKeptnInterfaceV2
+type localSequencesAPI struct {
+}
+
+func (l localSequencesAPI) ControlSequence(ctx context.Context, params apiv2.SequenceControlParams, opts apiv2.SequencesControlSequenceOptions) error {
+	return ErrNotAvailableLocally
+}
+
+// localServicesAPI is the Services API of localKeptnInterfaceV2
+type localServicesAPI struct {
+}
+
+func (l localServicesAPI) CreateServiceInStage(ctx context.Context, project string, stage string, serviceName string, opts apiv2.ServicesCreateServiceInStageOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localServicesAPI) DeleteServiceFromStage(ctx context.Context, project string, stage string, serviceName string, opts apiv2.ServicesDeleteServiceFromStageOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localServicesAPI) GetService(ctx context.Context, project, stage, service string, opts apiv2.ServicesGetServiceOptions) (*models.Service, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localServicesAPI) GetAllServices(ctx context.Context, project string, stage string, opts apiv2.ServicesGetAllServicesOptions) ([]*models.Service, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localStagesAPI is the Stages API of localKeptnInterfaceV2
+type localStagesAPI struct {
+}
+
+func (l localStagesAPI) CreateStage(ctx context.Context, project string, stageName string, opts apiv2.StagesCreateStageOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localStagesAPI) GetAllStages(ctx context.Context, project string, opts apiv2.StagesGetAllStagesOptions) ([]*models.Stage, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localUniformAPI is the Uniform API of localKeptnInterfaceV2
+type localUniformAPI struct {
+}
+
+func (l localUniformAPI) Ping(ctx context.Context, integrationID string, opts apiv2.UniformPingOptions) (*models.Integration, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localUniformAPI) RegisterIntegration(ctx context.Context, integration models.Integration, opts apiv2.UniformRegisterIntegrationOptions) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localUniformAPI) CreateSubscription(ctx context.Context, integrationID string, subscription models.EventSubscription, opts apiv2.UniformCreateSubscriptionOptions) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localUniformAPI) UnregisterIntegration(ctx context.Context, integrationID string, opts apiv2.UniformUnregisterIntegrationOptions) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localUniformAPI) GetRegistrations(ctx context.Context, opts apiv2.UniformGetRegistrationsOptions) ([]*models.Integration, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localShipyardControlAPI is the ShipyardControl API of localKeptnInterfaceV2
+type localShipyardControlAPI struct {
+}
+
+func (l localShipyardControlAPI) GetOpenTriggeredEvents(ctx context.Context, filter apiv2.EventFilter, opts apiv2.ShipyardControlGetOpenTriggeredEventsOptions) ([]*models.KeptnContextExtendedCE, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+// localResourcesAPI is the resources API of localKeptnInterfaceV2
+type localResourcesAPI struct {
+}
+
+func (l localResourcesAPI) CreateResources(ctx context.Context, project string, stage string, service string, resources []*models.Resource, opts apiv2.ResourcesCreateResourcesOptions) (*models.EventContext, *models.Error) {
+	return nil, notAvailableLocally()
+}
+
+func (l localResourcesAPI) CreateProjectResources(ctx context.Context, project string, resources []*models.Resource, opts apiv2.ResourcesCreateProjectResourcesOptions) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesAPI) UpdateProjectResources(ctx context.Context, project string, resources []*models.Resource, opts apiv2.ResourcesUpdateProjectResourcesOptions) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesAPI) UpdateServiceResources(ctx context.Context, project string, stage string, service string, resources []*models.Resource, opts apiv2.ResourcesUpdateServiceResourcesOptions) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesAPI) GetAllStageResources(ctx context.Context, project string, stage string, opts apiv2.ResourcesGetAllStageResourcesOptions) ([]*models.Resource, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localResourcesAPI) GetAllServiceResources(ctx context.Context, project string, stage string, service string, opts apiv2.ResourcesGetAllServiceResourcesOptions) ([]*models.Resource, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localResourcesAPI) GetResource(ctx context.Context, scope apiv2.ResourceScope, opts apiv2.ResourcesGetResourceOptions) (*models.Resource, error) {
+	return nil, ErrNotAvailableLocally
+}
+
+func (l localResourcesAPI) DeleteResource(ctx context.Context, scope apiv2.ResourceScope, opts apiv2.ResourcesDeleteResourceOptions) error {
+	return ErrNotAvailableLocally
+}
+
+func (l localResourcesAPI) UpdateResource(ctx context.Context, resource *models.Resource, scope apiv2.ResourceScope, opts apiv2.ResourcesUpdateResourceOptions) (string, error) {
+	return "", ErrNotAvailableLocally
+}
+
+func (l localResourcesAPI) CreateResource(ctx context.Context, resource []*models.Resource, scope apiv2.ResourceScope, opts apiv2.ResourcesCreateResourceOptions) (string, error) {
+	return "", ErrNotAvailableLocally
+}
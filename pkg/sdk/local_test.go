@@ -0,0 +1,119 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mtx    sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.buffer.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.buffer.String()
+}
+
+func Test_NewLocalKeptn(t *testing.T) {
+	dir := t.TempDir()
+	content, err := json.Marshal(newTestTaskTriggeredEvent())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "event.json"), content, 0600))
+
+	cfg := DefaultConfig()
+	output := &syncBuffer{}
+	handler := &TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		return FakeTaskData{}, nil
+	}}
+	keptn, err := NewLocalKeptn("local", cfg, LocalOptions{EventsDirectory: dir, Output: output, ExitWhenDone: true},
+		WithTaskHandler("sh.keptn.event.faketask.triggered", handler))
+	require.NoError(t, err)
+
+	// the control plane stops as soon as all events have been read
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), gracefulShutdownKey, &nopWG{}), 5*time.Second)
+	defer cancel()
+	require.NoError(t, keptn.controlPlane.Register(ctx, keptn))
+	require.NoError(t, ctx.Err())
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(output.String(), "sh.keptn.event.faketask.finished")
+	}, time.Second, 10*time.Millisecond)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "sh.keptn.event.faketask.started")
+}
+
+func Test_NewLocalKeptn_DoesNotUseKeptnAPI(t *testing.T) {
+	keptn, err := NewLocalKeptn("local", DefaultConfig(), LocalOptions{})
+	require.NoError(t, err)
+
+	require.False(t, keptn.env.HealthEndpointEnabled)
+	_, err = keptn.GetResourceHandler().GetResource(*api.NewResourceScope().Project("prj").Stage("stg").Service("svc").Resource("file"))
+	require.ErrorIs(t, err, ErrNotAvailableLocally)
+	_, err = keptn.GetSecret("unknown", "token")
+	require.ErrorIs(t, err, ErrSecretNotFound)
+	_, mErr := keptn.APIV2().Events().GetEvents(context.TODO(), &apiv2.EventFilter{}, apiv2.EventsGetEventsOptions{})
+	require.NotNil(t, mErr)
+	require.Equal(t, int64(501), mErr.Code)
+	_, err = keptn.APIV1().LogsV1().GetLogs(models.GetLogsParams{})
+	require.ErrorIs(t, err, ErrNotAvailableLocally)
+	err = keptn.GetTaskResult(KeptnEvent(newTestTaskTriggeredEvent()), "faketask", &FakeTaskData{})
+	require.ErrorContains(t, err, ErrNotAvailableLocally.Error())
+}
+
+func Test_NewLocalKeptn_OverlappingSubscriptionsProcessEventsOnce(t *testing.T) {
+	dir := t.TempDir()
+	content, err := json.Marshal(newTestTaskTriggeredEvent())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "event.json"), content, 0600))
+
+	output := &syncBuffer{}
+	handler := &TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		return FakeTaskData{}, nil
+	}}
+	observed := make(chan string, 10)
+	observer := EventObserverFunc(func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) {
+		observed <- event.ID
+	})
+	keptn, err := NewLocalKeptn("local", DefaultConfig(), LocalOptions{EventsDirectory: dir, Output: output, ExitWhenDone: true},
+		WithTaskHandler("sh.keptn.event.faketask.triggered", handler),
+		WithTaskEventHandler("sh.keptn.event.*.triggered", handler, TaskHandlerOptions{}),
+		WithEventObserver("sh.keptn.event.*.triggered", observer))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), gracefulShutdownKey, &nopWG{}), 5*time.Second)
+	defer cancel()
+	require.NoError(t, keptn.controlPlane.Register(ctx, keptn))
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(output.String(), "sh.keptn.event.faketask.finished")
+	}, time.Second, 10*time.Millisecond)
+	// give duplicate deliveries the chance to be processed
+	time.Sleep(100 * time.Millisecond)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "sh.keptn.event.faketask.started")
+	require.Contains(t, lines[1], "sh.keptn.event.faketask.finished")
+	require.Len(t, observed, 1)
+}
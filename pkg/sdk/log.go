@@ -1,8 +1,24 @@
 package sdk
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// Keys of the fields added to the logger returned by IKeptn.EventLogger
+const (
+	FieldKeptnContext = "shkeptncontext"
+	FieldTriggeredID  = "triggeredid"
+	FieldEventType    = "type"
+	FieldProject      = "project"
+	FieldStage        = "stage"
+	FieldService      = "service"
 )
 
 // Logger interface used by the go sdk
@@ -19,6 +35,131 @@ type Logger interface {
 	Fatalf(format string, v ...interface{})
 }
 
+// Fields are key value pairs added to every entry logged by a StructuredLogger
+type Fields map[string]interface{}
+
+// StructuredLogger is a Logger that is able to attach fields to the logged entries
+type StructuredLogger interface {
+	Logger
+	// WithFields returns a logger adding the given fields to every entry, in addition to the fields of the current logger
+	WithFields(fields Fields) StructuredLogger
+}
+
+// eventFields returns the keptn context, triggered ID, event type, project, stage and service of the given event as Fields
+func eventFields(event models.KeptnContextExtendedCE) Fields {
+	fields := Fields{
+		FieldKeptnContext: event.Shkeptncontext,
+		FieldTriggeredID:  triggeredID(event),
+	}
+	if event.Type != nil {
+		fields[FieldEventType] = *event.Type
+	}
+	eventData := keptnv2.EventData{}
+	if err := keptnv2.EventDataAs(event, &eventData); err == nil {
+		fields[FieldProject] = eventData.Project
+		fields[FieldStage] = eventData.Stage
+		fields[FieldService] = eventData.Service
+	}
+	return fields
+}
+
+// triggeredID returns the ID of the .triggered event the given event belongs to
+func triggeredID(event models.KeptnContextExtendedCE) string {
+	if event.Type != nil && keptnv2.IsTriggeredEventType(*event.Type) {
+		return event.ID
+	}
+	return event.Triggeredid
+}
+
+// withFields adds the given fields to the given logger. Loggers not implementing StructuredLogger
+// get the fields prepended to their messages
+func withFields(logger Logger, fields Fields) StructuredLogger {
+	if structuredLogger, ok := logger.(StructuredLogger); ok {
+		return structuredLogger.WithFields(fields)
+	}
+	return fieldsLogger{logger: logger, fields: fields}
+}
+
+// fieldsLogger is a StructuredLogger prepending its fields to the messages passed to the wrapped Logger
+type fieldsLogger struct {
+	logger Logger
+	fields Fields
+}
+
+func (f fieldsLogger) WithFields(fields Fields) StructuredLogger {
+	merged := Fields{}
+	for key, value := range f.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return fieldsLogger{logger: f.logger, fields: merged}
+}
+
+// prefix returns the fields formatted as key=value pairs, sorted by key
+func (f fieldsLogger) prefix() string {
+	keys := make([]string, 0, len(f.fields))
+	for key := range f.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, f.fields[key]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (f fieldsLogger) args(v []interface{}) []interface{} {
+	return []interface{}{f.prefix() + " " + fmt.Sprint(v...)}
+}
+
+// format formats the message before prefixing it, so that the field values are not interpreted as format verbs
+func (f fieldsLogger) format(format string, v []interface{}) string {
+	return f.prefix() + " " + fmt.Sprintf(format, v...)
+}
+
+func (f fieldsLogger) Debug(v ...interface{}) {
+	f.logger.Debug(f.args(v)...)
+}
+
+func (f fieldsLogger) Debugf(format string, v ...interface{}) {
+	f.logger.Debug(f.format(format, v))
+}
+
+func (f fieldsLogger) Info(v ...interface{}) {
+	f.logger.Info(f.args(v)...)
+}
+
+func (f fieldsLogger) Infof(format string, v ...interface{}) {
+	f.logger.Info(f.format(format, v))
+}
+
+func (f fieldsLogger) Warn(v ...interface{}) {
+	f.logger.Warn(f.args(v)...)
+}
+
+func (f fieldsLogger) Warnf(format string, v ...interface{}) {
+	f.logger.Warn(f.format(format, v))
+}
+
+func (f fieldsLogger) Error(v ...interface{}) {
+	f.logger.Error(f.args(v)...)
+}
+
+func (f fieldsLogger) Errorf(format string, v ...interface{}) {
+	f.logger.Error(f.format(format, v))
+}
+
+func (f fieldsLogger) Fatal(v ...interface{}) {
+	f.logger.Fatal(f.args(v)...)
+}
+
+func (f fieldsLogger) Fatalf(format string, v ...interface{}) {
+	f.logger.Fatal(f.format(format, v))
+}
+
 // DefaultLogger implementation of Logger using the go log package
 type DefaultLogger struct {
 	logger *log.Logger
@@ -29,6 +170,11 @@ func newDefaultLogger() *DefaultLogger {
 	return &DefaultLogger{logger: log.New(os.Stdout, "", 5)}
 }
 
+// WithFields returns a logger prepending the given fields to the logged messages
+func (d DefaultLogger) WithFields(fields Fields) StructuredLogger {
+	return fieldsLogger{logger: d, fields: fields}
+}
+
 func (d DefaultLogger) Debug(v ...interface{}) {
 	d.logger.Println(v...)
 }
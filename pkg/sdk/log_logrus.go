@@ -0,0 +1,58 @@
+package sdk
+
+import "github.com/sirupsen/logrus"
+
+// LogrusLogger is a StructuredLogger using logrus
+type LogrusLogger struct {
+	logger logrus.FieldLogger
+}
+
+// NewLogrusLogger creates a new LogrusLogger using the given logrus logger or entry
+func NewLogrusLogger(logger logrus.FieldLogger) *LogrusLogger {
+	return &LogrusLogger{logger: logger}
+}
+
+// WithFields returns a logger adding the given fields to the logged entries
+func (l *LogrusLogger) WithFields(fields Fields) StructuredLogger {
+	return &LogrusLogger{logger: l.logger.WithFields(logrus.Fields(fields))}
+}
+
+func (l *LogrusLogger) Debug(v ...interface{}) {
+	l.logger.Debug(v...)
+}
+
+func (l *LogrusLogger) Debugf(format string, v ...interface{}) {
+	l.logger.Debugf(format, v...)
+}
+
+func (l *LogrusLogger) Info(v ...interface{}) {
+	l.logger.Info(v...)
+}
+
+func (l *LogrusLogger) Infof(format string, v ...interface{}) {
+	l.logger.Infof(format, v...)
+}
+
+func (l *LogrusLogger) Warn(v ...interface{}) {
+	l.logger.Warn(v...)
+}
+
+func (l *LogrusLogger) Warnf(format string, v ...interface{}) {
+	l.logger.Warnf(format, v...)
+}
+
+func (l *LogrusLogger) Error(v ...interface{}) {
+	l.logger.Error(v...)
+}
+
+func (l *LogrusLogger) Errorf(format string, v ...interface{}) {
+	l.logger.Errorf(format, v...)
+}
+
+func (l *LogrusLogger) Fatal(v ...interface{}) {
+	l.logger.Fatal(v...)
+}
+
+func (l *LogrusLogger) Fatalf(format string, v ...interface{}) {
+	l.logger.Fatalf(format, v...)
+}
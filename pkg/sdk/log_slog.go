@@ -0,0 +1,79 @@
+//go:build go1.21
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// SlogLogger is a StructuredLogger using log/slog.
+// Since log/slog has been added to the standard library with Go 1.21, while this module still supports Go 1.20,
+// SlogLogger is only available when building with Go 1.21 or newer
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a new SlogLogger using the given slog logger
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	return &SlogLogger{logger: logger}
+}
+
+// WithFields returns a logger adding the given fields as attributes to the logged records
+func (l *SlogLogger) WithFields(fields Fields) StructuredLogger {
+	args := make([]any, 0, 2*len(fields))
+	for key, value := range fields {
+		args = append(args, key, value)
+	}
+	return &SlogLogger{logger: l.logger.With(args...)}
+}
+
+func (l *SlogLogger) log(level slog.Level, msg string) {
+	l.logger.Log(context.Background(), level, msg)
+}
+
+func (l *SlogLogger) Debug(v ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprint(v...))
+}
+
+func (l *SlogLogger) Debugf(format string, v ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprintf(format, v...))
+}
+
+func (l *SlogLogger) Info(v ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprint(v...))
+}
+
+func (l *SlogLogger) Infof(format string, v ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (l *SlogLogger) Warn(v ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprint(v...))
+}
+
+func (l *SlogLogger) Warnf(format string, v ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, v...))
+}
+
+func (l *SlogLogger) Error(v ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprint(v...))
+}
+
+func (l *SlogLogger) Errorf(format string, v ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(format, v...))
+}
+
+// Fatal logs the message at error level and exits, as slog does not know a fatal level
+func (l *SlogLogger) Fatal(v ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprint(v...))
+	os.Exit(1)
+}
+
+// Fatalf logs the message at error level and exits, as slog does not know a fatal level
+func (l *SlogLogger) Fatalf(format string, v ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(format, v...))
+	os.Exit(1)
+}
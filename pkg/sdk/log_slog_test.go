@@ -0,0 +1,29 @@
+//go:build go1.21
+
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EventLogger_Slog(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.logger = NewSlogLogger(slog.New(slog.NewJSONHandler(buffer, nil)))
+	fakeKeptn.Keptn.EventLogger(KeptnEvent(newTestEvent("sh.keptn.event.test.triggered"))).Warnf("handling %s", "task")
+
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+	require.Equal(t, "handling task", entry["msg"])
+	require.Equal(t, "WARN", entry["level"])
+	require.Equal(t, "context", entry[FieldKeptnContext])
+	require.Equal(t, "id", entry[FieldTriggeredID])
+	require.Equal(t, "prj", entry[FieldProject])
+	require.Equal(t, "stg", entry[FieldStage])
+	require.Equal(t, "svc", entry[FieldService])
+}
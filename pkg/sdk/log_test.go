@@ -0,0 +1,48 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EventLogger_PrependsFieldsForPlainLoggers(t *testing.T) {
+	logger := &recordingLogger{}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.logger = logger
+
+	eventLogger := fakeKeptn.Keptn.EventLogger(KeptnEvent(newTestEvent("sh.keptn.event.test.triggered")))
+	eventLogger.Infof("handling %s", "task")
+	eventLogger.WithFields(Fields{"attempt": 2}).Info("retrying")
+	eventLogger.WithFields(Fields{"progress": "50%d"}).Warnf("%d steps left", 3)
+
+	require.Equal(t, []string{
+		"project=prj service=svc shkeptncontext=context stage=stg triggeredid=id type=sh.keptn.event.test.triggered handling task",
+		"attempt=2 project=prj service=svc shkeptncontext=context stage=stg triggeredid=id type=sh.keptn.event.test.triggered retrying",
+		"progress=50%d project=prj service=svc shkeptncontext=context stage=stg triggeredid=id type=sh.keptn.event.test.triggered 3 steps left",
+	}, logger.lines)
+}
+
+func Test_EventLogger_Logrus(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(buffer)
+	logrusLogger.SetFormatter(&logrus.JSONFormatter{})
+
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.logger = NewLogrusLogger(logrusLogger)
+	fakeKeptn.Keptn.EventLogger(KeptnEvent(newTestEvent("sh.keptn.event.test.triggered"))).Infof("handling %s", "task")
+
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+	require.Equal(t, "handling task", entry["msg"])
+	require.Equal(t, "context", entry[FieldKeptnContext])
+	require.Equal(t, "id", entry[FieldTriggeredID])
+	require.Equal(t, "sh.keptn.event.test.triggered", entry[FieldEventType])
+	require.Equal(t, "prj", entry[FieldProject])
+	require.Equal(t, "stg", entry[FieldStage])
+	require.Equal(t, "svc", entry[FieldService])
+}
@@ -0,0 +1,173 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// LogLevel is the severity of a log entry
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// LogForwardingOptions configures which entries logged via IKeptn.EventLogger are forwarded to the Keptn log ingestion API
+type LogForwardingOptions struct {
+	// Level is the minimum level of the forwarded entries
+	Level LogLevel
+}
+
+// WithLogForwarding enables forwarding the entries logged via IKeptn.EventLogger at or above the configured level
+// to the Keptn log ingestion API, so that they show up in the Bridge. The entries are cached and sent periodically
+// by the log handler of the Keptn API, as well as when the sdk shuts down or a fatal entry is logged
+func WithLogForwarding(options LogForwardingOptions) KeptnOption {
+	return func(k *Keptn) {
+		k.logForwarding = &options
+	}
+}
+
+// startLogForwarding starts the periodic flushing of the cached log entries
+func (k *Keptn) startLogForwarding(ctx context.Context) {
+	if k.logForwarding == nil {
+		return
+	}
+	k.api.LogsV1().Start(ctx)
+}
+
+// flushForwardedLogs sends the log entries that are still cached
+func (k *Keptn) flushForwardedLogs() {
+	if k.logForwarding == nil {
+		return
+	}
+	if err := k.api.LogsV1().Flush(); err != nil {
+		k.logger.Errorf("Unable to flush forwarded logs: %v", err)
+	}
+}
+
+// forwardingLogger wraps the given logger so that the logged entries are additionally forwarded for the given event,
+// if log forwarding is enabled and the ID of the integration is known
+func (k *Keptn) forwardingLogger(logger StructuredLogger, event models.KeptnContextExtendedCE) StructuredLogger {
+	if k.logForwarding == nil {
+		return logger
+	}
+	integrationID := k.registeredIntegrationID()
+	if integrationID == "" {
+		return logger
+	}
+	taskName, _, _ := keptnv2.ParseTaskEventType(*event.Type)
+	return logForwardingLogger{
+		logger: logger,
+		level:  k.logForwarding.Level,
+		log:    k.api.LogsV1().Log,
+		flush:  k.api.LogsV1().Flush,
+		redact: k.secretRedactor.redact,
+		entry: models.LogEntry{
+			IntegrationID: integrationID,
+			KeptnContext:  event.Shkeptncontext,
+			Task:          taskName,
+			TriggeredID:   triggeredID(event),
+		},
+	}
+}
+
+// registeredIntegrationID returns the ID the integration has been registered with, as passed along with the received
+// events. It is known as soon as the first event has been received, so that the entries logged for an event are
+// forwarded regardless of whether they are logged by a task handler, an event observer or during the shutdown
+func (k *Keptn) registeredIntegrationID() string {
+	integrationID, _ := k.integrationID.Load().(string)
+	return integrationID
+}
+
+// logForwardingLogger is a StructuredLogger passing the logged entries at or above the configured level
+// to the log ingestion API, in addition to the wrapped logger
+type logForwardingLogger struct {
+	logger StructuredLogger
+	level  LogLevel
+	log    func(logs []models.LogEntry)
+	flush  func() error
+	redact func(message string) string
+	entry  models.LogEntry
+}
+
+func (l logForwardingLogger) forward(level LogLevel, message string) {
+	if level < l.level {
+		return
+	}
+	entry := l.entry
+	entry.Message = l.redact(message)
+	entry.Time = time.Now().UTC()
+	l.log([]models.LogEntry{entry})
+}
+
+func (l logForwardingLogger) WithFields(fields Fields) StructuredLogger {
+	l.logger = l.logger.WithFields(fields)
+	return l
+}
+
+func (l logForwardingLogger) Debug(v ...interface{}) {
+	l.logger.Debug(v...)
+	l.forward(LogLevelDebug, fmt.Sprint(v...))
+}
+
+func (l logForwardingLogger) Debugf(format string, v ...interface{}) {
+	l.logger.Debugf(format, v...)
+	l.forward(LogLevelDebug, fmt.Sprintf(format, v...))
+}
+
+func (l logForwardingLogger) Info(v ...interface{}) {
+	l.logger.Info(v...)
+	l.forward(LogLevelInfo, fmt.Sprint(v...))
+}
+
+func (l logForwardingLogger) Infof(format string, v ...interface{}) {
+	l.logger.Infof(format, v...)
+	l.forward(LogLevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (l logForwardingLogger) Warn(v ...interface{}) {
+	l.logger.Warn(v...)
+	l.forward(LogLevelWarn, fmt.Sprint(v...))
+}
+
+func (l logForwardingLogger) Warnf(format string, v ...interface{}) {
+	l.logger.Warnf(format, v...)
+	l.forward(LogLevelWarn, fmt.Sprintf(format, v...))
+}
+
+func (l logForwardingLogger) Error(v ...interface{}) {
+	l.logger.Error(v...)
+	l.forward(LogLevelError, fmt.Sprint(v...))
+}
+
+func (l logForwardingLogger) Errorf(format string, v ...interface{}) {
+	l.logger.Errorf(format, v...)
+	l.forward(LogLevelError, fmt.Sprintf(format, v...))
+}
+
+// forwardNow forwards the entry and sends the cached entries right away, since the program is about to terminate
+func (l logForwardingLogger) forwardNow(message string) {
+	l.forward(LogLevelError, message)
+	if err := l.flush(); err != nil {
+		l.logger.Errorf("Unable to flush forwarded logs: %v", err)
+	}
+}
+
+// Fatal forwards the entry before passing it to the wrapped logger, which is expected to terminate the program
+func (l logForwardingLogger) Fatal(v ...interface{}) {
+	l.forwardNow(fmt.Sprint(v...))
+	l.logger.Fatal(v...)
+}
+
+// Fatalf forwards the entry before passing it to the wrapped logger, which is expected to terminate the program
+func (l logForwardingLogger) Fatalf(format string, v ...interface{}) {
+	l.forwardNow(fmt.Sprintf(format, v...))
+	l.logger.Fatalf(format, v...)
+}
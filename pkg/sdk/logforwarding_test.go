@@ -0,0 +1,119 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	utils_mock "github.com/keptn/go-utils/pkg/api/utils/fake"
+	"github.com/stretchr/testify/require"
+)
+
+type logForwardingKeptnInterface struct {
+	panicKeptnInterface
+	logs *utils_mock.ILogHandlerMock
+}
+
+func (l logForwardingKeptnInterface) LogsV1() api.LogsV1Interface {
+	return l.logs
+}
+
+func newLogForwardingTestKeptn(handler TaskHandler) (*FakeKeptn, *utils_mock.ILogHandlerMock) {
+	logs := &utils_mock.ILogHandlerMock{LogFunc: func(logs []models.LogEntry) {}}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetAPI(logForwardingKeptnInterface{logs: logs})
+	fakeKeptn.AddTaskHandler("sh.keptn.event.test.triggered", handler)
+	return fakeKeptn, logs
+}
+
+func Test_LogForwarding(t *testing.T) {
+	handler := &TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		logger := keptnHandle.EventLogger(event)
+		logger.Debug("not forwarded")
+		logger.Infof("deploying %s", "svc")
+		logger.WithFields(Fields{"attempt": 1}).Error("deployment failed")
+		return FakeTaskData{}, nil
+	}}
+	fakeKeptn, logs := newLogForwardingTestKeptn(handler)
+	fakeKeptn.IntegrationID = "integration-id"
+	fakeKeptn.SetLogForwarding(LogForwardingOptions{Level: LogLevelInfo})
+
+	require.NoError(t, fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.test.triggered")))
+
+	calls := logs.LogCalls()
+	require.Len(t, calls, 2)
+	messages := []string{}
+	for _, call := range calls {
+		require.Len(t, call.Logs, 1)
+		entry := call.Logs[0]
+		require.Equal(t, "integration-id", entry.IntegrationID)
+		require.Equal(t, "context", entry.KeptnContext)
+		require.Equal(t, "test", entry.Task)
+		require.Equal(t, "id", entry.TriggeredID)
+		require.False(t, entry.Time.IsZero())
+		messages = append(messages, entry.Message)
+	}
+	require.Equal(t, []string{"deploying svc", "deployment failed"}, messages)
+}
+
+func Test_LogForwarding_DisabledOrUnknownIntegrationID(t *testing.T) {
+	handler := &TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		keptnHandle.EventLogger(event).Error("deployment failed")
+		return FakeTaskData{}, nil
+	}}
+
+	t.Run("disabled", func(t *testing.T) {
+		fakeKeptn, logs := newLogForwardingTestKeptn(handler)
+		fakeKeptn.IntegrationID = "integration-id"
+		require.NoError(t, fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.test.triggered")))
+		require.Empty(t, logs.LogCalls())
+	})
+	t.Run("unknown integration ID", func(t *testing.T) {
+		fakeKeptn, logs := newLogForwardingTestKeptn(handler)
+		fakeKeptn.SetLogForwarding(LogForwardingOptions{Level: LogLevelDebug})
+		require.NoError(t, fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.test.triggered")))
+		require.Empty(t, logs.LogCalls())
+	})
+}
+
+func Test_LogForwarding_EntriesLoggedOutsideOfTaskHandler(t *testing.T) {
+	fakeKeptn, logs := newLogForwardingTestKeptn(&TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		return FakeTaskData{}, nil
+	}})
+	fakeKeptn.IntegrationID = "integration-id"
+	fakeKeptn.SetLogForwarding(LogForwardingOptions{Level: LogLevelInfo})
+	fakeKeptn.AddEventObserver("sh.keptn.event.test.finished", EventObserverFunc(func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) {
+		keptnHandle.EventLogger(event).Info("observed")
+	}))
+
+	require.NoError(t, fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.test.finished")))
+	// e.g. during the shutdown, after the task has finished
+	fakeKeptn.Keptn.EventLogger(KeptnEvent(newTestEvent("sh.keptn.event.test.triggered"))).Warn("shutting down")
+
+	calls := logs.LogCalls()
+	require.Len(t, calls, 2)
+	require.Equal(t, "integration-id", calls[0].Logs[0].IntegrationID)
+	require.Equal(t, "observed", calls[0].Logs[0].Message)
+	require.Equal(t, "integration-id", calls[1].Logs[0].IntegrationID)
+	require.Equal(t, "shutting down", calls[1].Logs[0].Message)
+}
+
+func Test_LogForwarding_FatalFlushesEntries(t *testing.T) {
+	fakeKeptn, logs := newLogForwardingTestKeptn(&TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		keptnHandle.EventLogger(event).Fatalf("unrecoverable error: %s", "disk full")
+		return FakeTaskData{}, nil
+	}})
+	logs.FlushFunc = func() error { return nil }
+	logger := &recordingLogger{}
+	fakeKeptn.Keptn.logger = logger
+	fakeKeptn.IntegrationID = "integration-id"
+	fakeKeptn.SetLogForwarding(LogForwardingOptions{Level: LogLevelError})
+
+	require.NoError(t, fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.test.triggered")))
+
+	require.Len(t, logs.LogCalls(), 1)
+	require.Equal(t, "unrecoverable error: disk full", logs.LogCalls()[0].Logs[0].Message)
+	require.Len(t, logs.FlushCalls(), 1)
+	require.True(t, logger.contains("unrecoverable error: disk full"))
+}
@@ -0,0 +1,41 @@
+package sdk
+
+import (
+	"github.com/keptn/go-utils/pkg/common/observability"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// WithMeterProvider configures the sdk to record its metrics using the given meter provider.
+// Per default, the sdk records its metrics using a meter provider exposing them in the Prometheus format
+// under the path '/metrics' on the port of the health endpoint. When a custom meter provider is configured,
+// the '/metrics' path is not served, since the metrics are exported by the given meter provider
+func WithMeterProvider(meterProvider metric.MeterProvider) KeptnOption {
+	return func(k *Keptn) {
+		k.meterProvider = meterProvider
+	}
+}
+
+// initMetrics creates the instruments of the sdk and the control plane connector components.
+// If no meter provider has been configured, a meter provider exposing the metrics in the Prometheus format is set up
+func (k *Keptn) initMetrics() {
+	if k.meterProvider == nil {
+		meterProvider, metricsHandler, err := observability.NewPrometheusMeterProvider(k.source)
+		if err != nil {
+			k.logger.Errorf("Unable to set up metrics: %v", err)
+			k.metrics = metrics.NewDefault()
+			return
+		}
+		k.meterProvider = meterProvider
+		k.metricsHandler = metricsHandler
+	}
+	k.metrics = metrics.New(k.meterProvider)
+}
+
+// handlerResult returns the result of a task handler execution used to label the recorded metrics
+func handlerResult(err *Error) string {
+	if err != nil {
+		return metrics.ResultFailure
+	}
+	return metrics.ResultSuccess
+}
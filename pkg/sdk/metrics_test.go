@@ -0,0 +1,64 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/metrics"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func Test_WhenReceivingEvents_MetricsAreRecorded(t *testing.T) {
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) { return FakeTaskData{}, nil }
+	reader := sdkmetric.NewManualReader()
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler, func(keptnHandle IKeptn, event KeptnEvent) bool {
+		return event.ID != "filtered"
+	})
+
+	for _, id := range []string{"id-1", "id-2", "filtered"} {
+		fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+			Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+			ID:             id,
+			Shkeptncontext: "context",
+			Source:         strutils.Stringp("source"),
+			Type:           strutils.Stringp("sh.keptn.event.faketask.triggered"),
+		})
+	}
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "unhandled",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.othertask.triggered"),
+	})
+
+	rm := metricdata.ResourceMetrics{}
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	values := map[string]map[string]int64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		if !ok {
+			continue
+		}
+		values[m.Name] = map[string]int64{}
+		for _, dp := range sum.DataPoints {
+			label, ok := dp.Attributes.Value(metrics.AttributeResult)
+			if !ok {
+				label, _ = dp.Attributes.Value(metrics.AttributeReason)
+			}
+			values[m.Name][label.AsString()] = dp.Value
+		}
+	}
+
+	require.Equal(t, map[string]int64{metrics.ResultSuccess: 2}, values["keptn.sdk.events.handled"])
+	require.Equal(t, map[string]int64{metrics.ReasonFiltered: 1, metrics.ReasonNoHandler: 1}, values["keptn.sdk.events.dropped"])
+}
@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+)
+
+// TaskHandlerFunc is the execution of a task handler as it is seen by a TaskMiddleware
+type TaskHandlerFunc func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error)
+
+// TaskMiddleware wraps the execution of a task handler. A middleware can run logic before and after calling next,
+// short-circuit the execution by not calling next at all, or modify the result and *Error returned by next
+type TaskMiddleware func(next TaskHandlerFunc) TaskHandlerFunc
+
+// WithTaskMiddleware registers middlewares that wrap the execution of every registered task handler.
+// The middlewares are applied in the given order, i.e. the first middleware is the outermost one.
+// Calling WithTaskMiddleware multiple times appends the given middlewares to the already registered ones
+func WithTaskMiddleware(middlewares ...TaskMiddleware) KeptnOption {
+	return func(k *Keptn) {
+		k.taskMiddlewares = append(k.taskMiddlewares, middlewares...)
+	}
+}
+
+// chainMiddlewares wraps the given task execution with the given middlewares
+func chainMiddlewares(fn TaskHandlerFunc, middlewares []TaskMiddleware) TaskHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		fn = middlewares[i](fn)
+	}
+	return fn
+}
+
+// LoggingMiddleware logs the start and the outcome of every task handler execution using the logger of the sdk
+func LoggingMiddleware() TaskMiddleware {
+	return func(next TaskHandlerFunc) TaskHandlerFunc {
+		return func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+			keptnHandle.Logger().Infof("Executing task handler for event %s of type %s (keptn context %s)", event.ID, *event.Type, event.Shkeptncontext)
+			result, err := next(ctx, keptnHandle, event)
+			if err != nil {
+				keptnHandle.Logger().Errorf("Task handler for event %s failed: %s", event.ID, err.Message)
+			} else {
+				keptnHandle.Logger().Infof("Task handler for event %s succeeded", event.ID)
+			}
+			return result, err
+		}
+	}
+}
+
+// TimingMiddleware logs the duration of every task handler execution using the logger of the sdk
+func TimingMiddleware() TaskMiddleware {
+	return func(next TaskHandlerFunc) TaskHandlerFunc {
+		return func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+			start := time.Now()
+			defer func() {
+				keptnHandle.Logger().Infof("Task handler for event %s of type %s took %s", event.ID, *event.Type, time.Since(start))
+			}()
+			return next(ctx, keptnHandle, event)
+		}
+	}
+}
+
+// RecoveryMiddleware recovers from panics of the wrapped task handler and turns them into an *Error.
+// Note, that the sdk always recovers from panics of task handlers. This middleware can be used to recover
+// at a specific position of the middleware chain, e.g. to allow outer middlewares to inspect the resulting *Error
+func RecoveryMiddleware() TaskMiddleware {
+	return func(next TaskHandlerFunc) TaskHandlerFunc {
+		return func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (result interface{}, err *Error) {
+			defer func() {
+				if r := recover(); r != nil {
+					keptnHandle.Logger().Errorf("Recovered from panic in task handler for event %s: %v\n%s", event.ID, r, debug.Stack())
+					result, err = nil, newPanicError(r)
+				}
+			}()
+			return next(ctx, keptnHandle, event)
+		}
+	}
+}
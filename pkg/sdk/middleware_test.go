@@ -0,0 +1,153 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TaskMiddlewaresAreAppliedInOrder(t *testing.T) {
+	calls := []string{}
+	recordingMiddleware := func(name string) TaskMiddleware {
+		return func(next TaskHandlerFunc) TaskHandlerFunc {
+			return func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+				calls = append(calls, "before "+name)
+				result, err := next(ctx, keptnHandle, event)
+				calls = append(calls, "after "+name)
+				return result, err
+			}
+		}
+	}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		calls = append(calls, "handler")
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskMiddleware(recordingMiddleware("first"), recordingMiddleware("second"))
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	require.Equal(t, []string{"before first", "before second", "handler", "after second", "after first"}, calls)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+}
+
+func Test_TaskMiddlewareShortCircuitsExecution(t *testing.T) {
+	authMiddleware := func(next TaskHandlerFunc) TaskHandlerFunc {
+		return func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+			return nil, &Error{StatusType: v0_2_0.StatusErrored, ResultType: v0_2_0.ResultFailed, Message: "not authorized", Err: fmt.Errorf("not authorized")}
+		}
+	}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		t.Fatal("task handler must not be executed")
+		return nil, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	WithTaskMiddleware(authMiddleware)(fakeKeptn.Keptn)
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusErrored)
+}
+
+func Test_TaskMiddlewareModifiesResult(t *testing.T) {
+	labelMiddleware := func(next TaskHandlerFunc) TaskHandlerFunc {
+		return func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+			result, err := next(ctx, keptnHandle, event)
+			if err != nil {
+				// downgrade failures of the handler to warnings
+				return v0_2_0.EventData{Result: v0_2_0.ResultWarning, Message: err.Message}, nil
+			}
+			return result, nil
+		}
+	}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		return nil, &Error{StatusType: v0_2_0.StatusErrored, ResultType: v0_2_0.ResultFailed, Message: "failed", Err: fmt.Errorf("failed")}
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskMiddleware(labelMiddleware)
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusSucceeded)
+	fakeKeptn.AssertSentEventResult(t, 1, v0_2_0.ResultWarning)
+}
+
+func Test_BuiltInTaskMiddlewares(t *testing.T) {
+	logger := &recordingLogger{}
+	var innerErr *Error
+	inspectingMiddleware := func(next TaskHandlerFunc) TaskHandlerFunc {
+		return func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+			result, err := next(ctx, keptnHandle, event)
+			innerErr = err
+			return result, err
+		}
+	}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		panic("boom")
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.logger = logger
+	fakeKeptn.AddTaskMiddleware(LoggingMiddleware(), TimingMiddleware(), inspectingMiddleware, RecoveryMiddleware())
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	require.NotNil(t, innerErr)
+	require.ErrorIs(t, innerErr.Err, ErrTaskPanic)
+	require.True(t, logger.contains("Executing task handler for event id"))
+	require.True(t, logger.contains("Task handler for event id failed: task handler panicked: boom"))
+	require.True(t, logger.contains("Task handler for event id of type sh.keptn.event.faketask.triggered took"))
+	fakeKeptn.AssertNumberOfEventSent(t, 3)
+	fakeKeptn.AssertSentEventType(t, 1, v0_2_0.ErrorLogEventName)
+	fakeKeptn.AssertSentEventStatus(t, 2, v0_2_0.StatusErrored)
+}
+
+type recordingLogger struct {
+	mtx   sync.Mutex
+	lines []string
+}
+
+func (r *recordingLogger) record(line string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.lines = append(r.lines, line)
+}
+
+func (r *recordingLogger) contains(substr string) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, line := range r.lines {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *recordingLogger) Debug(v ...interface{}) { r.record(fmt.Sprint(v...)) }
+func (r *recordingLogger) Debugf(format string, v ...interface{}) {
+	r.record(fmt.Sprintf(format, v...))
+}
+func (r *recordingLogger) Info(v ...interface{})                 { r.record(fmt.Sprint(v...)) }
+func (r *recordingLogger) Infof(format string, v ...interface{}) { r.record(fmt.Sprintf(format, v...)) }
+func (r *recordingLogger) Warn(v ...interface{})                 { r.record(fmt.Sprint(v...)) }
+func (r *recordingLogger) Warnf(format string, v ...interface{}) { r.record(fmt.Sprintf(format, v...)) }
+func (r *recordingLogger) Error(v ...interface{})                { r.record(fmt.Sprint(v...)) }
+func (r *recordingLogger) Errorf(format string, v ...interface{}) {
+	r.record(fmt.Sprintf(format, v...))
+}
+func (r *recordingLogger) Fatal(v ...interface{}) { r.record(fmt.Sprint(v...)) }
+func (r *recordingLogger) Fatalf(format string, v ...interface{}) {
+	r.record(fmt.Sprintf(format, v...))
+}
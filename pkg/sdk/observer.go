@@ -0,0 +1,123 @@
+package sdk
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// EventObserver receives events without responding to them, e.g. to audit the processing of sequences or to send
+// notifications. In contrast to a TaskHandler, an EventObserver can be registered for any event type, e.g. .started and
+// .finished events or sequence events, and the sdk does not send any .started or .finished event on its behalf
+type EventObserver interface {
+	// Observe is called whenever an event matching the event type the observer has been registered for is received
+	Observe(ctx context.Context, keptnHandle IKeptn, event KeptnEvent)
+}
+
+// EventObserverFunc is an adapter to allow the use of ordinary functions as EventObserver
+type EventObserverFunc func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent)
+
+// Observe calls f(ctx, keptnHandle, event)
+func (f EventObserverFunc) Observe(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) {
+	f(ctx, keptnHandle, event)
+}
+
+// WithEventObserver registers an EventObserver for the given event type. Just like for task handlers, a pattern such as
+// sh.keptn.event.*.finished can be given instead of a concrete event type. Every observer whose event type matches a
+// received event is notified, in addition to the task handler responsible for the event, if any.
+// The observer is only notified if all given filters return true
+func WithEventObserver(eventType string, observer EventObserver, filters ...func(keptnHandle IKeptn, event KeptnEvent) bool) KeptnOption {
+	return func(k *Keptn) {
+		k.observerRegistry.Add(eventType, observerEntry{observer: observer, eventFilters: filters})
+	}
+}
+
+type observerEntry struct {
+	eventType string
+	observer  EventObserver
+	// eventFilters is a list of functions that are executed before an event is passed to the observer. Only if all functions return 'true', the observer is notified
+	eventFilters []func(keptnHandle IKeptn, event KeptnEvent) bool
+}
+
+type observerRegistry struct {
+	sync.RWMutex
+	entries []observerEntry
+}
+
+func newObserverRegistry() *observerRegistry {
+	return &observerRegistry{}
+}
+
+func (o *observerRegistry) Add(eventType string, entry observerEntry) {
+	o.Lock()
+	defer o.Unlock()
+	entry.eventType = eventType
+	o.entries = append(o.entries, entry)
+}
+
+// EventTypes returns the event types the observers have been registered for, in order of registration
+func (o *observerRegistry) EventTypes() []string {
+	o.RLock()
+	defer o.RUnlock()
+	eventTypes := make([]string, 0, len(o.entries))
+	for _, entry := range o.entries {
+		eventTypes = append(eventTypes, entry.eventType)
+	}
+	return eventTypes
+}
+
+// Matching returns the entries of all observers registered for the given event type
+func (o *observerRegistry) Matching(eventType string) []observerEntry {
+	o.RLock()
+	defer o.RUnlock()
+	matching := []observerEntry{}
+	for _, entry := range o.entries {
+		if entry.eventType == eventType || (isEventTypePattern(entry.eventType) && matchEventType(entry.eventType, eventType)) {
+			matching = append(matching, entry)
+		}
+	}
+	return matching
+}
+
+// notifyObservers passes the given event to all matching observers and returns whether any observer matched
+func (k *Keptn) notifyObservers(ctx context.Context, event models.KeptnContextExtendedCE) bool {
+	observers := k.observerRegistry.Matching(*event.Type)
+	if len(observers) == 0 {
+		return false
+	}
+	wg, ok := ctx.Value(gracefulShutdownKey).(wgInterface)
+	if !ok {
+		k.logger.Errorf("Unable to get graceful shutdown wait group. Skip notifying observers of event %s", event.ID)
+		return true
+	}
+	keptnEvent := &KeptnEvent{}
+	if err := keptnv2.Decode(&event, keptnEvent); err != nil {
+		k.logger.Errorf("Unable to decode event %s. Skip notifying observers: %v", event.ID, err)
+		return true
+	}
+	wg.Add(1)
+	k.runEventTaskAction(func() {
+		defer wg.Done()
+		for _, entry := range observers {
+			k.notifyObserver(ctx, entry, *keptnEvent)
+		}
+	})
+	return true
+}
+
+func (k *Keptn) notifyObserver(ctx context.Context, entry observerEntry, event KeptnEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			k.logger.Errorf("Recovered from panic in observer for event %s: %v\n%s", event.ID, r, debug.Stack())
+		}
+	}()
+	for _, filterFn := range entry.eventFilters {
+		if !filterFn(k, event) {
+			return
+		}
+	}
+	entry.observer.Observe(ctx, k, event)
+}
@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	observed []string
+}
+
+func (r *recordingObserver) Observe(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) {
+	r.observed = append(r.observed, *event.Type)
+}
+
+func Test_WhenReceivingEvents_ObserversAreNotifiedWithoutResponding(t *testing.T) {
+	finishedObserver := &recordingObserver{}
+	sequenceObserver := &recordingObserver{}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddEventObserver("sh.keptn.event.*.finished", finishedObserver)
+	fakeKeptn.AddEventObserver("sh.keptn.event.dev.delivery.finished", sequenceObserver)
+
+	for _, eventType := range []string{
+		"sh.keptn.event.deployment.finished",
+		"sh.keptn.event.deployment.started",
+		"sh.keptn.event.test.finished",
+		"sh.keptn.event.dev.delivery.finished",
+	} {
+		fakeKeptn.NewEvent(newTestEvent(eventType))
+	}
+
+	require.Equal(t, []string{"sh.keptn.event.deployment.finished", "sh.keptn.event.test.finished"}, finishedObserver.observed)
+	require.Equal(t, []string{"sh.keptn.event.dev.delivery.finished"}, sequenceObserver.observed)
+	fakeKeptn.AssertNumberOfEventSent(t, 0)
+}
+
+func Test_WhenReceivingATriggeredEvent_ObserverAndTaskHandlerAreExecuted(t *testing.T) {
+	observer := &recordingObserver{}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) { return FakeTaskData{}, nil }
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+	fakeKeptn.AddEventObserver("*", observer)
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	require.Equal(t, []string{"sh.keptn.event.faketask.triggered"}, observer.observed)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
+}
+
+func Test_WhenReceivingEvents_ObserverFiltersAreApplied(t *testing.T) {
+	observer := &recordingObserver{}
+	panickingObserver := EventObserverFunc(func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) {
+		panic("boom")
+	})
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddEventObserver("sh.keptn.event.deployment.finished", panickingObserver)
+	fakeKeptn.AddEventObserver("sh.keptn.event.deployment.finished", observer, func(keptnHandle IKeptn, event KeptnEvent) bool {
+		return event.Data.(map[string]interface{})["project"] == "prj"
+	})
+
+	otherProject := newTestEvent("sh.keptn.event.deployment.finished")
+	otherProject.Data = v0_2_0.EventData{Project: "other-prj", Stage: "stg", Service: "svc"}
+	require.NotPanics(t, func() {
+		fakeKeptn.NewEvent(otherProject)
+		fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.deployment.finished"))
+	})
+
+	require.Equal(t, []string{"sh.keptn.event.deployment.finished"}, observer.observed)
+	fakeKeptn.AssertNumberOfEventSent(t, 0)
+}
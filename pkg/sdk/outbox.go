@@ -0,0 +1,372 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+)
+
+const (
+	defaultOutboxInitialBackoff = time.Second
+	defaultOutboxMaxBackoff     = time.Minute
+)
+
+// OutboxStore persists the events that could not be sent yet
+type OutboxStore interface {
+	// Add appends the given event to the end of the outbox
+	Add(event models.KeptnContextExtendedCE) error
+	// List returns the stored events in the order they have been added
+	List() ([]models.KeptnContextExtendedCE, error)
+	// Remove removes the event with the given ID from the outbox
+	Remove(eventID string) error
+}
+
+// OutboxOptions configures the outbox keeping events that could not be sent, e.g. because the event broker or
+// the Keptn API is unavailable
+type OutboxOptions struct {
+	// Store keeps the events that have not been sent yet
+	Store OutboxStore
+	// InitialBackoff is the time waited before sending the stored events is retried. Defaults to one second
+	InitialBackoff time.Duration
+	// MaxBackoff limits the time waited between retries, which is doubled after every failed retry. Defaults to one minute
+	MaxBackoff time.Duration
+}
+
+// WithOutbox enables the outbox for outgoing events. Events that cannot be sent are stored and sent again
+// in order, until they have been delivered. While the outbox is not empty, new events are added to the
+// outbox as well, so that the order of the events is kept. Remaining events are sent when the sdk shuts down,
+// events that still cannot be sent are kept in the store
+func WithOutbox(options OutboxOptions) KeptnOption {
+	return func(k *Keptn) {
+		if options.InitialBackoff <= 0 {
+			options.InitialBackoff = defaultOutboxInitialBackoff
+		}
+		if options.MaxBackoff <= 0 {
+			options.MaxBackoff = defaultOutboxMaxBackoff
+		}
+		k.outboxOptions = &options
+	}
+}
+
+// initOutbox creates the outbox, if it has been enabled via WithOutbox
+func (k *Keptn) initOutbox() {
+	if k.outboxOptions != nil {
+		k.outbox = newOutbox(*k.outboxOptions, k.logger)
+	}
+}
+
+// OutboxStatus describes the events waiting in the outbox
+type OutboxStatus struct {
+	Backlog int `json:"backlog"`
+}
+
+type outbox struct {
+	// mtx guards the store and the senders, flushMtx ensures that the stored events are only sent by one flush at a time
+	mtx      sync.Mutex
+	flushMtx sync.Mutex
+	options  OutboxOptions
+	logger   Logger
+	// senders holds the senders the stored events have originally been passed to.
+	// Events loaded from the store after a restart are sent using the default sender
+	senders map[string]controlplane.EventSender
+	// backlog is the number of stored events. It is read from the store once and kept up to date on every
+	// Add and Remove, so that the store does not need to be listed for every sent event
+	backlog int
+	notify  chan struct{}
+}
+
+func newOutbox(options OutboxOptions, logger Logger) *outbox {
+	o := &outbox{
+		options: options,
+		logger:  logger,
+		senders: map[string]controlplane.EventSender{},
+		notify:  make(chan struct{}, 1),
+	}
+	events, err := options.Store.List()
+	if err != nil {
+		logger.Errorf("Unable to read outbox: %v", err)
+	}
+	o.backlog = len(events)
+	return o
+}
+
+// outboxSender returns an EventSender passing events to the given sender via the outbox, if it is enabled
+func (k *Keptn) outboxSender(sender controlplane.EventSender) controlplane.EventSender {
+	if k.outbox == nil {
+		return sender
+	}
+	return func(ce models.KeptnContextExtendedCE) error {
+		return k.outbox.send(sender, ce)
+	}
+}
+
+// send passes the given event to the given sender. If the outbox is not empty or sending fails, the event is stored.
+// The outbox is only locked while its backlog is checked and appended, so that events are sent concurrently
+func (o *outbox) send(sender controlplane.EventSender, event models.KeptnContextExtendedCE) error {
+	if o.status().Backlog == 0 {
+		sendErr := sender(event)
+		if sendErr == nil {
+			return nil
+		}
+		o.logger.Warnf("Unable to send event %s, adding it to the outbox: %v", event.ID, sendErr)
+	}
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if err := o.options.Store.Add(event); err != nil {
+		return fmt.Errorf("could not add event %s to outbox: %w", event.ID, err)
+	}
+	o.backlog++
+	o.senders[event.ID] = sender
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// flush sends the stored events in order, until all have been sent or sending an event fails.
+// Events added while flushing are sent as well, since they are queued behind the flushed ones
+func (o *outbox) flush(defaultSender controlplane.EventSender) error {
+	o.flushMtx.Lock()
+	defer o.flushMtx.Unlock()
+	for {
+		events, senders, err := o.pending(defaultSender)
+		if err != nil {
+			return fmt.Errorf("could not read outbox: %w", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		for i, event := range events {
+			if err := senders[i](event); err != nil {
+				return fmt.Errorf("could not send event %s: %w", event.ID, err)
+			}
+			if err := o.remove(event.ID); err != nil {
+				return fmt.Errorf("could not remove event %s from outbox: %w", event.ID, err)
+			}
+		}
+	}
+}
+
+// pending returns the stored events together with the senders they have originally been passed to
+func (o *outbox) pending(defaultSender controlplane.EventSender) ([]models.KeptnContextExtendedCE, []controlplane.EventSender, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if o.backlog == 0 {
+		return nil, nil, nil
+	}
+	events, err := o.options.Store.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	senders := make([]controlplane.EventSender, len(events))
+	for i, event := range events {
+		sender, ok := o.senders[event.ID]
+		if !ok {
+			sender = defaultSender
+		}
+		senders[i] = sender
+	}
+	return events, senders, nil
+}
+
+func (o *outbox) remove(eventID string) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if err := o.options.Store.Remove(eventID); err != nil {
+		return err
+	}
+	delete(o.senders, eventID)
+	o.backlog--
+	return nil
+}
+
+// run sends the stored events until the given context is done, backing off after every failed attempt
+func (o *outbox) run(ctx context.Context, defaultSender controlplane.EventSender) {
+	backoff := o.options.InitialBackoff
+	for {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := o.flush(defaultSender); err != nil {
+			o.logger.Warnf("Unable to send events from outbox: %v", err)
+			backoff *= 2
+			if backoff > o.options.MaxBackoff {
+				backoff = o.options.MaxBackoff
+			}
+			continue
+		}
+		backoff = o.options.InitialBackoff
+		if o.status().Backlog > 0 {
+			continue
+		}
+		// wait until an event is added to the outbox
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.notify:
+		}
+	}
+}
+
+func (o *outbox) status() OutboxStatus {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return OutboxStatus{Backlog: o.backlog}
+}
+
+// startOutbox starts sending the events stored in the outbox in the background
+func (k *Keptn) startOutbox(ctx context.Context) {
+	if k.outbox == nil {
+		return
+	}
+	go k.outbox.run(ctx, k.eventSender)
+}
+
+// flushOutbox sends the remaining events stored in the outbox
+func (k *Keptn) flushOutbox() {
+	if k.outbox == nil {
+		return
+	}
+	if err := k.outbox.flush(k.eventSender); err != nil {
+		k.logger.Errorf("Unable to send remaining events from outbox, %d event(s) are kept: %v", k.outbox.status().Backlog, err)
+	}
+}
+
+// InMemoryOutboxStore is an OutboxStore keeping the events in memory.
+// Events that have not been sent when the service stops are lost
+type InMemoryOutboxStore struct {
+	mtx    sync.Mutex
+	events []models.KeptnContextExtendedCE
+}
+
+// NewInMemoryOutboxStore creates a new InMemoryOutboxStore
+func NewInMemoryOutboxStore() *InMemoryOutboxStore {
+	return &InMemoryOutboxStore{}
+}
+
+// Add appends the given event to the end of the outbox
+func (s *InMemoryOutboxStore) Add(event models.KeptnContextExtendedCE) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+// List returns the stored events in the order they have been added
+func (s *InMemoryOutboxStore) List() ([]models.KeptnContextExtendedCE, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]models.KeptnContextExtendedCE{}, s.events...), nil
+}
+
+// Remove removes the event with the given ID from the outbox
+func (s *InMemoryOutboxStore) Remove(eventID string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for i, event := range s.events {
+		if event.ID == eventID {
+			s.events = append(s.events[:i], s.events[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// FileOutboxStore is an OutboxStore persisting every event as JSON file in a local directory,
+// e.g. located on a persistent volume, so that the events survive restarts of the service
+type FileOutboxStore struct {
+	mtx  sync.Mutex
+	dir  string
+	next uint64
+	// files holds the names of the stored files, in order
+	files []string
+}
+
+// NewFileOutboxStore creates a new FileOutboxStore persisting the events in the directory with the given path.
+// Events already contained in the directory are loaded
+func NewFileOutboxStore(dir string) (*FileOutboxStore, error) {
+	s := &FileOutboxStore{dir: filepath.Clean(dir)}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create outbox directory: %w", err)
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read outbox directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.SplitN(entry.Name(), "-", 2)[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if seq >= s.next {
+			s.next = seq + 1
+		}
+		s.files = append(s.files, entry.Name())
+	}
+	// the zero-padded sequence number prefix keeps the lexical order of the files equal to the order they have been added in
+	sort.Strings(s.files)
+	return s, nil
+}
+
+// Add appends the given event to the end of the outbox
+func (s *FileOutboxStore) Add(event models.KeptnContextExtendedCE) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	name := fmt.Sprintf("%020d-%s.json", s.next, event.ID)
+	if err := writeJSONFile(filepath.Join(s.dir, name), event); err != nil {
+		return fmt.Errorf("could not write event: %w", err)
+	}
+	s.next++
+	s.files = append(s.files, name)
+	return nil
+}
+
+// List returns the stored events in the order they have been added
+func (s *FileOutboxStore) List() ([]models.KeptnContextExtendedCE, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	events := make([]models.KeptnContextExtendedCE, 0, len(s.files))
+	for _, name := range s.files {
+		event := models.KeptnContextExtendedCE{}
+		if err := readJSONFile(filepath.Join(s.dir, name), &event); err != nil {
+			return nil, fmt.Errorf("could not read event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Remove removes the event with the given ID from the outbox
+func (s *FileOutboxStore) Remove(eventID string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for i, name := range s.files {
+		if strings.TrimSuffix(strings.SplitN(name, "-", 2)[1], ".json") != eventID {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not remove event: %w", err)
+		}
+		s.files = append(s.files[:i], s.files[i+1:]...)
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"github.com/keptn/go-utils/pkg/sdk/connector/types"
+	"github.com/stretchr/testify/require"
+)
+
+// unreliableSender records the events passed to it while it is available and fails otherwise
+type unreliableSender struct {
+	mtx         sync.Mutex
+	unavailable bool
+	sent        []models.KeptnContextExtendedCE
+}
+
+func (u *unreliableSender) setUnavailable(unavailable bool) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	u.unavailable = unavailable
+}
+
+func (u *unreliableSender) send(ce models.KeptnContextExtendedCE) error {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if u.unavailable {
+		return errors.New("event broker unavailable")
+	}
+	u.sent = append(u.sent, ce)
+	return nil
+}
+
+func (u *unreliableSender) sentTypes() []string {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	eventTypes := []string{}
+	for _, ce := range u.sent {
+		eventTypes = append(eventTypes, *ce.Type)
+	}
+	return eventTypes
+}
+
+func newOutboxTestEvent(id string) models.KeptnContextExtendedCE {
+	return models.KeptnContextExtendedCE{ID: id, Type: strutils.Stringp("sh.keptn.event.faketask.started")}
+}
+
+func Test_Outbox_EventsAreSentInOrderWhenTheSenderIsAvailableAgain(t *testing.T) {
+	sender := &unreliableSender{unavailable: true}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", &TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		return FakeTaskData{}, nil
+	}})
+	fakeKeptn.SetOutbox(OutboxOptions{Store: NewInMemoryOutboxStore()})
+
+	ctx := context.WithValue(context.TODO(), types.EventSenderKey, controlplane.EventSender(sender.send))
+	ctx = context.WithValue(ctx, gracefulShutdownKey, &nopWG{})
+	require.NoError(t, fakeKeptn.Keptn.OnEvent(ctx, newTestTaskTriggeredEvent()))
+
+	require.Empty(t, sender.sentTypes())
+	require.Equal(t, OutboxStatus{Backlog: 2}, fakeKeptn.Keptn.healthStatusDetails()["outbox"])
+
+	// the outbox is not empty, so that the event is added to the outbox even though the sender is available again
+	sender.setUnavailable(false)
+	require.NoError(t, fakeKeptn.Keptn.outboxSender(sender.send)(newOutboxTestEvent("other")))
+	require.Empty(t, sender.sentTypes())
+
+	fakeKeptn.Keptn.flushOutbox()
+	require.Equal(t, []string{"sh.keptn.event.faketask.started", "sh.keptn.event.faketask.finished", "sh.keptn.event.faketask.started"}, sender.sentTypes())
+	require.Equal(t, OutboxStatus{Backlog: 0}, fakeKeptn.Keptn.outbox.status())
+}
+
+func Test_Outbox_RunRetriesWithBackoff(t *testing.T) {
+	sender := &unreliableSender{unavailable: true}
+	o := newOutbox(OutboxOptions{Store: NewInMemoryOutboxStore(), InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}, newDefaultLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go o.run(ctx, sender.send)
+
+	require.NoError(t, o.send(sender.send, newOutboxTestEvent("1")))
+	require.NoError(t, o.send(sender.send, newOutboxTestEvent("2")))
+	require.Equal(t, 2, o.status().Backlog)
+
+	sender.setUnavailable(false)
+	require.Eventually(t, func() bool {
+		return o.status().Backlog == 0
+	}, time.Second, 5*time.Millisecond)
+	require.Len(t, sender.sentTypes(), 2)
+}
+
+func Test_Outbox_SendingDoesNotBlockOtherEvents(t *testing.T) {
+	o := newOutbox(OutboxOptions{Store: NewInMemoryOutboxStore()}, newDefaultLogger())
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = o.send(func(ce models.KeptnContextExtendedCE) error {
+			close(blocked)
+			<-release
+			return nil
+		}, newOutboxTestEvent("slow"))
+	}()
+	<-blocked
+	defer close(release)
+
+	sender := &unreliableSender{}
+	require.NoError(t, o.send(sender.send, newOutboxTestEvent("fast")))
+	require.Len(t, sender.sentTypes(), 1)
+	require.Equal(t, 0, o.status().Backlog)
+}
+
+// listCountingOutboxStore is an InMemoryOutboxStore counting how often the stored events are listed
+type listCountingOutboxStore struct {
+	*InMemoryOutboxStore
+	lists atomic.Int32
+}
+
+func (s *listCountingOutboxStore) List() ([]models.KeptnContextExtendedCE, error) {
+	s.lists.Add(1)
+	return s.InMemoryOutboxStore.List()
+}
+
+func Test_Outbox_BacklogIsNotReadFromTheStoreForEverySentEvent(t *testing.T) {
+	store := &listCountingOutboxStore{InMemoryOutboxStore: NewInMemoryOutboxStore()}
+	require.NoError(t, store.Add(newOutboxTestEvent("stored")))
+	o := newOutbox(OutboxOptions{Store: store}, newDefaultLogger())
+	require.Equal(t, 1, o.status().Backlog)
+
+	sender := &unreliableSender{}
+	require.NoError(t, o.flush(sender.send))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, o.send(sender.send, newOutboxTestEvent(strconv.Itoa(i))))
+		require.NoError(t, o.flush(sender.send))
+	}
+
+	require.Len(t, sender.sentTypes(), 11)
+	require.Equal(t, 0, o.status().Backlog)
+	// the store is listed once when the outbox is created and once by the flush sending the stored event
+	require.Equal(t, int32(2), store.lists.Load())
+}
+
+func TestFileOutboxStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileOutboxStore(dir)
+	require.NoError(t, err)
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, store.Add(newOutboxTestEvent(id)))
+	}
+	require.NoError(t, store.Remove("2"))
+
+	// events are loaded after a restart, and new events are appended after them
+	store, err = NewFileOutboxStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(newOutboxTestEvent("4")))
+
+	events, err := store.List()
+	require.NoError(t, err)
+	ids := []string{}
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	require.Equal(t, []string{"1", "3", "4"}, ids)
+}
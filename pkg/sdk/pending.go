@@ -0,0 +1,327 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+)
+
+// ErrPendingTaskNotFound is returned if no pending task with the requested handle is stored
+var ErrPendingTaskNotFound = errors.New("pending task not found")
+
+// PendingResult is returned by a task handler whose task is not done when the handler returns, e.g. because it waits for
+// a manual approval or an external pipeline run. Instead of sending a .finished event, the sdk persists the .triggered
+// event in the PendingTaskStore configured via WithPendingTasks. The task is completed later by passing the stored
+// event to SendFinishedEvent, e.g. from a callback, a poller or another instance of the service after a restart
+type PendingResult struct {
+	// Handle identifies the pending task, e.g. the ID of the external pipeline run
+	Handle string
+	// Metadata is stored along with the task and can be used to resume e.g. the polling of the task's state
+	Metadata map[string]string
+}
+
+// Pending creates a PendingResult with the given handle to be returned by a task handler
+func Pending(handle string) *PendingResult {
+	return &PendingResult{Handle: handle}
+}
+
+// PendingTask is the record kept for every task that has not been finished when its task handler returned
+type PendingTask struct {
+	// Handle identifies the pending task and has been set by the task handler
+	Handle string `json:"handle"`
+	// Event is the .triggered event the task has been started for
+	Event KeptnEvent `json:"event"`
+	// CreatedAt is the point in time the task handler returned the PendingResult
+	CreatedAt time.Time `json:"createdAt"`
+	// Metadata is the metadata set by the task handler
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// PendingTaskStore persists the tasks that have not been finished yet
+type PendingTaskStore interface {
+	// Add stores the given task, replacing a stored task with the same handle
+	Add(task PendingTask) error
+	// Get returns the task with the given handle or ErrPendingTaskNotFound
+	Get(handle string) (*PendingTask, error)
+	// GetByEventID returns the task started for the .triggered event with the given ID or ErrPendingTaskNotFound
+	GetByEventID(eventID string) (*PendingTask, error)
+	// List returns all stored tasks, ordered by the point in time they have been created
+	List() ([]PendingTask, error)
+	// Remove removes the task with the given handle. Removing a task that is not stored is not an error
+	Remove(handle string) error
+}
+
+// WithPendingTasks enables task handlers to return a PendingResult. The .triggered events of pending tasks are
+// kept in the given store until a .finished event is sent for them via SendFinishedEvent. Pending tasks of a
+// sequence are discarded if an .invalidated event or an aborted sequence .finished event is received
+func WithPendingTasks(store PendingTaskStore) KeptnOption {
+	return func(k *Keptn) {
+		k.pendingTasks = store
+	}
+}
+
+// PendingTask returns the pending task with the given handle. Pass its event to SendFinishedEvent to complete it
+func (k *Keptn) PendingTask(handle string) (*PendingTask, error) {
+	if k.pendingTasks == nil {
+		return nil, ErrPendingTaskNotFound
+	}
+	return k.pendingTasks.Get(handle)
+}
+
+// PendingTasks returns all pending tasks, e.g. to resume polling their state after a restart
+func (k *Keptn) PendingTasks() ([]PendingTask, error) {
+	if k.pendingTasks == nil {
+		return []PendingTask{}, nil
+	}
+	return k.pendingTasks.List()
+}
+
+// suspendTask stores the given event as pending task, so that it can be finished later on. If the task cannot be
+// stored, the task is reported as errored, since nobody would be able to finish it
+func (k *Keptn) suspendTask(eventSender controlplane.EventSender, event models.KeptnContextExtendedCE, pending *PendingResult, autoResponse bool, reservation *idempotencyReservation) {
+	err := errors.New("no pending task store configured")
+	if k.pendingTasks != nil {
+		err = k.pendingTasks.Add(PendingTask{
+			Handle:    pending.Handle,
+			Event:     KeptnEvent(event),
+			CreatedAt: time.Now(),
+			Metadata:  pending.Metadata,
+		})
+	}
+	if err == nil {
+		k.logger.Infof("Task for event %s is pending with handle %s", event.ID, pending.Handle)
+		reservation.keep()
+		return
+	}
+
+	k.logger.Errorf("Unable to store pending task %s for event %s: %v", pending.Handle, event.ID, err)
+	if !autoResponse || !keptnv2.IsTaskEventType(*event.Type) || !keptnv2.IsTriggeredEventType(*event.Type) {
+		reservation.complete(nil)
+		return
+	}
+	errorEvent, err := keptnv2.CreateErrorEvent(k.source, event, nil, &keptnv2.Error{
+		StatusType: keptnv2.StatusErrored,
+		ResultType: keptnv2.ResultFailed,
+		Message:    fmt.Sprintf("unable to store pending task %s: %v", pending.Handle, err),
+	})
+	if err != nil {
+		k.logger.Errorf("Unable to create '.error' event: %v", err)
+		return
+	}
+	reservation.complete(errorEvent)
+	if err := eventSender(*errorEvent); err != nil {
+		k.logger.Errorf("Unable to send '.error' event: %v", err)
+	}
+}
+
+// completePendingTask removes the pending task of the given event, if any, after a .finished event has been sent for it
+func (k *Keptn) completePendingTask(event models.KeptnContextExtendedCE, finishedEvent models.KeptnContextExtendedCE) {
+	if k.pendingTasks == nil {
+		return
+	}
+	task, err := k.pendingTasks.GetByEventID(event.ID)
+	if errors.Is(err, ErrPendingTaskNotFound) {
+		return
+	}
+	if err != nil {
+		k.logger.Errorf("Unable to read pending task of event %s: %v", event.ID, err)
+		return
+	}
+	if err := k.pendingTasks.Remove(task.Handle); err != nil {
+		k.logger.Errorf("Unable to remove pending task %s: %v", task.Handle, err)
+		return
+	}
+	k.completeIdempotencyRecord(event, &finishedEvent)
+}
+
+// discardPendingTasks removes the pending tasks belonging to the given keptn context and returns how many have been removed
+func (k *Keptn) discardPendingTasks(keptnContext string) int {
+	if k.pendingTasks == nil {
+		return 0
+	}
+	tasks, err := k.pendingTasks.List()
+	if err != nil {
+		k.logger.Errorf("Unable to read pending tasks: %v", err)
+		return 0
+	}
+	discarded := 0
+	for _, task := range tasks {
+		if task.Event.Shkeptncontext != keptnContext {
+			continue
+		}
+		if err := k.pendingTasks.Remove(task.Handle); err != nil {
+			k.logger.Errorf("Unable to remove pending task %s: %v", task.Handle, err)
+			continue
+		}
+		discarded++
+	}
+	return discarded
+}
+
+// InMemoryPendingTaskStore is a PendingTaskStore keeping the tasks in memory. It is suited for tasks that
+// are finished by the same instance of the service, since the tasks do not survive a restart
+type InMemoryPendingTaskStore struct {
+	mtx   sync.Mutex
+	tasks map[string]PendingTask
+	// handles maps the IDs of the events of the stored tasks to their handles
+	handles map[string]string
+}
+
+// NewInMemoryPendingTaskStore creates a new InMemoryPendingTaskStore
+func NewInMemoryPendingTaskStore() *InMemoryPendingTaskStore {
+	return &InMemoryPendingTaskStore{tasks: map[string]PendingTask{}, handles: map[string]string{}}
+}
+
+// Add stores the given task, replacing a stored task with the same handle
+func (s *InMemoryPendingTaskStore) Add(task PendingTask) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if existing, ok := s.tasks[task.Handle]; ok {
+		delete(s.handles, existing.Event.ID)
+	}
+	s.tasks[task.Handle] = task
+	s.handles[task.Event.ID] = task.Handle
+	return nil
+}
+
+// GetByEventID returns the task started for the event with the given ID or ErrPendingTaskNotFound
+func (s *InMemoryPendingTaskStore) GetByEventID(eventID string) (*PendingTask, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	task, ok := s.tasks[s.handles[eventID]]
+	if !ok {
+		return nil, fmt.Errorf("%w: event %s", ErrPendingTaskNotFound, eventID)
+	}
+	return &task, nil
+}
+
+// Get returns the task with the given handle or ErrPendingTaskNotFound
+func (s *InMemoryPendingTaskStore) Get(handle string) (*PendingTask, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	task, ok := s.tasks[handle]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPendingTaskNotFound, handle)
+	}
+	return &task, nil
+}
+
+// List returns all stored tasks, ordered by the point in time they have been created
+func (s *InMemoryPendingTaskStore) List() ([]PendingTask, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	tasks := make([]PendingTask, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].Handle < tasks[j].Handle
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+	return tasks, nil
+}
+
+// Remove removes the task with the given handle
+func (s *InMemoryPendingTaskStore) Remove(handle string) error {
+	s.remove(handle)
+	return nil
+}
+
+// remove removes the task with the given handle and returns it, if it has been stored
+func (s *InMemoryPendingTaskStore) remove(handle string) (PendingTask, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	task, ok := s.tasks[handle]
+	delete(s.tasks, handle)
+	if ok && s.handles[task.Event.ID] == handle {
+		delete(s.handles, task.Event.ID)
+	}
+	return task, ok
+}
+
+// FilePendingTaskStore is a PendingTaskStore persisting the tasks as JSON in a local file,
+// e.g. located on a persistent volume, so that pending tasks can be finished after a restart of the service
+type FilePendingTaskStore struct {
+	mtx   sync.Mutex
+	path  string
+	store *InMemoryPendingTaskStore
+}
+
+// NewFilePendingTaskStore creates a new FilePendingTaskStore persisting the tasks in the file with the given path.
+// Tasks already contained in the file are loaded
+func NewFilePendingTaskStore(path string) (*FilePendingTaskStore, error) {
+	s := &FilePendingTaskStore{path: filepath.Clean(path), store: NewInMemoryPendingTaskStore()}
+	if err := readJSONFile(s.path, &s.store.tasks); err != nil {
+		return nil, fmt.Errorf("could not read pending tasks: %w", err)
+	}
+	for handle, task := range s.store.tasks {
+		s.store.handles[task.Event.ID] = handle
+	}
+	return s, nil
+}
+
+// Add stores the given task, replacing a stored task with the same handle.
+// If the file cannot be written, the previously stored tasks are kept
+func (s *FilePendingTaskStore) Add(task PendingTask) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	previous, replaced := s.store.remove(task.Handle)
+	_ = s.store.Add(task)
+	if err := s.persist(); err != nil {
+		s.store.remove(task.Handle)
+		if replaced {
+			_ = s.store.Add(previous)
+		}
+		return err
+	}
+	return nil
+}
+
+// Get returns the task with the given handle or ErrPendingTaskNotFound
+func (s *FilePendingTaskStore) Get(handle string) (*PendingTask, error) {
+	return s.store.Get(handle)
+}
+
+// GetByEventID returns the task started for the event with the given ID or ErrPendingTaskNotFound
+func (s *FilePendingTaskStore) GetByEventID(eventID string) (*PendingTask, error) {
+	return s.store.GetByEventID(eventID)
+}
+
+// List returns all stored tasks, ordered by the point in time they have been created
+func (s *FilePendingTaskStore) List() ([]PendingTask, error) {
+	return s.store.List()
+}
+
+// Remove removes the task with the given handle.
+// If the file cannot be written, the task is kept
+func (s *FilePendingTaskStore) Remove(handle string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	task, ok := s.store.remove(handle)
+	if !ok {
+		return nil
+	}
+	if err := s.persist(); err != nil {
+		_ = s.store.Add(task)
+		return err
+	}
+	return nil
+}
+
+func (s *FilePendingTaskStore) persist() error {
+	s.store.mtx.Lock()
+	defer s.store.mtx.Unlock()
+	if err := writeJSONFile(s.path, s.store.tasks); err != nil {
+		return fmt.Errorf("could not write pending tasks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package sdk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+func newPendingTaskHandler(handle string) *TaskHandlerMock {
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		return &PendingResult{Handle: handle, Metadata: map[string]string{"pipeline": "42"}}, nil
+	}
+	return taskHandler
+}
+
+func Test_WhenTaskIsPending_TaskIsFinishedAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pending.json")
+	store, err := NewFilePendingTaskStore(path)
+	require.Nil(t, err)
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetPendingTasks(store)
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", newPendingTaskHandler("run-42"))
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	fakeKeptn.AssertNumberOfEventSent(t, 1)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+
+	// a new instance of the service finishes the task
+	restartedStore, err := NewFilePendingTaskStore(path)
+	require.Nil(t, err)
+	restartedKeptn := NewFakeKeptn("fake")
+	restartedKeptn.SetPendingTasks(restartedStore)
+
+	pendingTasks, err := restartedKeptn.Keptn.PendingTasks()
+	require.Nil(t, err)
+	require.Len(t, pendingTasks, 1)
+	require.Equal(t, "run-42", pendingTasks[0].Handle)
+	require.Equal(t, map[string]string{"pipeline": "42"}, pendingTasks[0].Metadata)
+
+	task, err := restartedKeptn.Keptn.PendingTask("run-42")
+	require.Nil(t, err)
+	require.Nil(t, restartedKeptn.Keptn.SendFinishedEvent(task.Event, v0_2_0.EventData{Result: v0_2_0.ResultPass, Status: v0_2_0.StatusSucceeded}))
+
+	restartedKeptn.AssertNumberOfEventSent(t, 1)
+	restartedKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.finished")
+	restartedKeptn.AssertSentEventResult(t, 0, v0_2_0.ResultPass)
+	restartedKeptn.AssertSentEvent(t, 0, func(ce models.KeptnContextExtendedCE) bool {
+		return ce.Triggeredid == "id" && ce.Shkeptncontext == "context"
+	})
+	_, err = restartedKeptn.Keptn.PendingTask("run-42")
+	require.ErrorIs(t, err, ErrPendingTaskNotFound)
+
+	reloadedStore, err := NewFilePendingTaskStore(path)
+	require.Nil(t, err)
+	pendingTasks, err = reloadedStore.List()
+	require.Nil(t, err)
+	require.Empty(t, pendingTasks)
+}
+
+func Test_WhenTaskIsPendingWithoutStore_TaskIsReportedAsErrored(t *testing.T) {
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", newPendingTaskHandler("run-42"))
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusErrored)
+	fakeKeptn.AssertSentEventResult(t, 1, v0_2_0.ResultFailed)
+}
+
+func Test_WhenTaskIsPending_DuplicateIsSkippedUntilTaskIsFinished(t *testing.T) {
+	executions := 0
+	taskHandler := newPendingTaskHandler("run-42")
+	pendingFn := taskHandler.ExecuteFunc
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		executions++
+		return pendingFn(keptnHandle, event)
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetIdempotency(IdempotencyOptions{Store: NewInMemoryIdempotencyStore(), ReplayFinishedEvent: true})
+	fakeKeptn.SetPendingTasks(NewInMemoryPendingTaskStore())
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+	fakeKeptn.AssertNumberOfEventSent(t, 1)
+
+	task, err := fakeKeptn.Keptn.PendingTask("run-42")
+	require.Nil(t, err)
+	require.Nil(t, fakeKeptn.Keptn.SendFinishedEvent(task.Event, v0_2_0.EventData{Result: v0_2_0.ResultWarning, Status: v0_2_0.StatusSucceeded}))
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+	require.Equal(t, 1, executions)
+	fakeKeptn.AssertNumberOfEventSent(t, 3)
+	fakeKeptn.AssertSentEventType(t, 2, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEventResult(t, 2, v0_2_0.ResultWarning)
+}
+
+func Test_WhenSequenceIsAborted_PendingTasksAreDiscarded(t *testing.T) {
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetPendingTasks(NewInMemoryPendingTaskStore())
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", newPendingTaskHandler("run-42"))
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+	fakeKeptn.NewEvent(models.KeptnContextExtendedCE{
+		Data:           v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"},
+		ID:             "invalidated-id",
+		Shkeptncontext: "context",
+		Source:         strutils.Stringp("source"),
+		Type:           strutils.Stringp("sh.keptn.event.faketask.invalidated"),
+	})
+
+	pendingTasks, err := fakeKeptn.Keptn.PendingTasks()
+	require.Nil(t, err)
+	require.Empty(t, pendingTasks)
+}
+
+func TestInMemoryPendingTaskStore_GetByEventID(t *testing.T) {
+	store := NewInMemoryPendingTaskStore()
+	event := KeptnEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+	require.Nil(t, store.Add(PendingTask{Handle: "run-42", Event: event}))
+
+	task, err := store.GetByEventID("id")
+	require.Nil(t, err)
+	require.Equal(t, "run-42", task.Handle)
+
+	require.Nil(t, store.Remove("run-42"))
+	_, err = store.GetByEventID("id")
+	require.ErrorIs(t, err, ErrPendingTaskNotFound)
+}
+
+func TestFilePendingTaskStore_WriteFailureKeepsStoredTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pending.json")
+	store, err := NewFilePendingTaskStore(path)
+	require.Nil(t, err)
+	event := KeptnEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+	require.Nil(t, store.Add(PendingTask{Handle: "run-42", Event: event, Metadata: map[string]string{"attempt": "1"}}))
+
+	// the temporary file cannot be written if a directory is in its place
+	require.Nil(t, os.Mkdir(path+".tmp", 0700))
+
+	other := KeptnEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+	other.ID = "other-id"
+	require.Error(t, store.Add(PendingTask{Handle: "run-43", Event: other}))
+	_, err = store.Get("run-43")
+	require.ErrorIs(t, err, ErrPendingTaskNotFound)
+	_, err = store.GetByEventID("other-id")
+	require.ErrorIs(t, err, ErrPendingTaskNotFound)
+
+	require.Error(t, store.Add(PendingTask{Handle: "run-42", Event: event, Metadata: map[string]string{"attempt": "2"}}))
+	task, err := store.Get("run-42")
+	require.Nil(t, err)
+	require.Equal(t, "1", task.Metadata["attempt"])
+
+	require.Error(t, store.Remove("run-42"))
+	task, err = store.GetByEventID("id")
+	require.Nil(t, err)
+	require.Equal(t, "run-42", task.Handle)
+}
+
+type typedPendingTestResult struct {
+	PendingMarker
+	v0_2_0.EventData
+}
+
+func Test_WhenTypedTaskHandlerMarksTaskAsPending_TaskIsSuspended(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(fakeKeptn *FakeKeptn)
+	}{
+		{
+			name: "result embedding PendingMarker",
+			register: func(fakeKeptn *FakeKeptn) {
+				WithTypedTaskHandler[v0_2_0.EventData, typedPendingTestResult]("sh.keptn.event.faketask.triggered", TypedTaskHandlerFunc[v0_2_0.EventData, typedPendingTestResult](
+					func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent, data v0_2_0.EventData) (*typedPendingTestResult, *Error) {
+						result := &typedPendingTestResult{}
+						result.MarkPending(Pending("run-42"))
+						return result, nil
+					}))(fakeKeptn.Keptn)
+			},
+		},
+		{
+			name: "PendingResult",
+			register: func(fakeKeptn *FakeKeptn) {
+				WithTypedTaskHandler[v0_2_0.EventData, PendingResult]("sh.keptn.event.faketask.triggered", TypedTaskHandlerFunc[v0_2_0.EventData, PendingResult](
+					func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent, data v0_2_0.EventData) (*PendingResult, *Error) {
+						return Pending("run-42"), nil
+					}))(fakeKeptn.Keptn)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKeptn := NewFakeKeptn("fake")
+			fakeKeptn.SetPendingTasks(NewInMemoryPendingTaskStore())
+			tt.register(fakeKeptn)
+
+			fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.faketask.triggered"))
+
+			fakeKeptn.AssertNumberOfEventSent(t, 1)
+			fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+			pendingTasks, err := fakeKeptn.Keptn.PendingTasks()
+			require.Nil(t, err)
+			require.Len(t, pendingTasks, 1)
+			require.Equal(t, "run-42", pendingTasks[0].Handle)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+)
+
+// ErrTaskPanic is used as error of the *Error that is reported if a task handler panicked
+var ErrTaskPanic = errors.New("task handler panicked")
+
+// newPanicError creates the *Error that is reported for a recovered panic
+func newPanicError(recovered interface{}) *Error {
+	return &Error{
+		StatusType: keptnv2.StatusErrored,
+		ResultType: keptnv2.ResultFailed,
+		Message:    fmt.Sprintf("task handler panicked: %v", recovered),
+		Err:        ErrTaskPanic,
+	}
+}
+
+// recoverPanic recovers from a panic that occurred during processing of the given event outside the task handler.
+// The panic is logged and reported to Keptn with an .error.log event and, if automatic responses are enabled for the
+// task handler of the event, an errored .finished event
+func (k *Keptn) recoverPanic(eventSender controlplane.EventSender, event models.KeptnContextExtendedCE, autoResponse bool) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	k.logger.Errorf("Recovered from panic while processing event %s: %v\n%s", event.ID, r, debug.Stack())
+	panicErr := newPanicError(r)
+	k.sendErrorLogEvent(eventSender, event, panicErr.Message)
+	if !autoResponse {
+		return
+	}
+	errorEvent, err := keptnv2.CreateErrorEvent(k.source, event, nil, &keptnv2.Error{
+		StatusType: panicErr.StatusType,
+		ResultType: panicErr.ResultType,
+		Message:    panicErr.Message,
+		Err:        panicErr.Err,
+	})
+	if err != nil {
+		k.logger.Errorf("Unable to create '.error' event: %v", err)
+		return
+	}
+	if err := eventSender(*errorEvent); err != nil {
+		k.logger.Errorf("Unable to send '.error' event: %v", err)
+	}
+}
+
+// sendErrorLogEvent sends a sh.keptn.log.error event with the given message for the given parent event
+func (k *Keptn) sendErrorLogEvent(eventSender controlplane.EventSender, parentEvent models.KeptnContextExtendedCE, message string) {
+	// keptnv2.CreateErrorLogEvent creates a .finished event for .triggered parent events, so the event is built here
+	taskName, _, _ := keptnv2.ParseTaskEventType(*parentEvent.Type)
+	errorLogEvent := keptnv2.KeptnEvent(keptnv2.ErrorLogEventName, k.source, keptnv2.ErrorLogEvent{
+		Message: message,
+		Task:    taskName,
+	}).WithKeptnContext(parentEvent.Shkeptncontext).WithTriggeredID(parentEvent.ID).KeptnContextExtendedCE
+
+	if err := eventSender(errorLogEvent); err != nil {
+		k.logger.Errorf("Unable to send '.error.log' event: %v", err)
+	}
+}
+
+// runHandler executes the given attempt of the task handler wrapped by the registered middlewares and converts a panic of the handler into an *Error
+func (k *Keptn) runHandler(ctx context.Context, handler *taskEntry, event KeptnEvent, attempt int) (result interface{}, err *Error) {
+	ctx, span := k.startExecuteSpan(ctx, event)
+	defer func() {
+		endExecuteSpan(span, err)
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			k.logger.Errorf("Recovered from panic in task handler for event %s: %v\n%s", event.ID, r, debug.Stack())
+			result, err = nil, newPanicError(r)
+		}
+	}()
+	return chainMiddlewares(handler.execute, k.taskMiddlewares)(ctx, k.eventHandle(event, attempt), event)
+}
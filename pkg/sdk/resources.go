@@ -0,0 +1,242 @@
+package sdk
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+const (
+	defaultResourceCacheSize = 256
+	gitCommitIDQueryParam    = "gitCommitID"
+)
+
+// ResourceOptions configures how the ResourceHandler passed to task handlers fetches resources
+type ResourceOptions struct {
+	// IgnoreGitCommitID makes task handlers fetch the latest version of resources instead of the version
+	// of the git commit the processed event refers to
+	IgnoreGitCommitID bool
+	// CacheSize is the maximum number of resources cached. Defaults to 256
+	CacheSize int
+	// DisableCache turns off the caching of resources fetched for a git commit
+	DisableCache bool
+}
+
+// WithResourceOptions configures the ResourceHandler passed to task handlers. By default, task handlers fetch
+// resources at the git commit given in the processed event, so that they read the configuration the sequence
+// has been started with. Since the resources of a commit never change, they are cached
+func WithResourceOptions(options ResourceOptions) KeptnOption {
+	return func(k *Keptn) {
+		k.resourceOptions = options
+	}
+}
+
+type resourceHandlerWrapper struct {
+	resourceHandler apiv2.ResourcesInterface
+	// gitCommitID is the commit resources are fetched at. If empty, the latest version of resources is fetched
+	gitCommitID string
+	cache       *resourceCache
+}
+
+func newResourceHandlerWrapper(resourceHandler apiv2.ResourcesInterface, options ResourceOptions) *resourceHandlerWrapper {
+	rhw := &resourceHandlerWrapper{resourceHandler: resourceHandler}
+	if !options.DisableCache {
+		if options.CacheSize <= 0 {
+			options.CacheSize = defaultResourceCacheSize
+		}
+		rhw.cache = newResourceCache(options.CacheSize)
+	}
+	return rhw
+}
+
+// atCommit returns a resource handler fetching resources at the given git commit and sharing the cache of rhw
+func (rhw *resourceHandlerWrapper) atCommit(gitCommitID string) *resourceHandlerWrapper {
+	return &resourceHandlerWrapper{resourceHandler: rhw.resourceHandler, gitCommitID: gitCommitID, cache: rhw.cache}
+}
+
+func (rhw *resourceHandlerWrapper) GetResource(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+	v2Scope := apiv2.NewResourceScope().Project(scope.GetProject()).Stage(scope.GetStage()).Service(scope.GetService()).Resource(scope.GetResource())
+	uriOptions := make([]apiv2.URIOption, 0, len(options)+1)
+	for _, option := range options {
+		uriOptions = append(uriOptions, apiv2.URIOption(option))
+	}
+	if rhw.gitCommitID == "" {
+		return rhw.resourceHandler.GetResource(context.Background(), *v2Scope, apiv2.ResourcesGetResourceOptions{URIOptions: uriOptions})
+	}
+	uriOptions = append(uriOptions, withGitCommitID(rhw.gitCommitID))
+
+	// the effect of custom uri options is unknown, thus only resources fetched without them are cached
+	if rhw.cache == nil || len(options) > 0 {
+		return rhw.resourceHandler.GetResource(context.Background(), *v2Scope, apiv2.ResourcesGetResourceOptions{URIOptions: uriOptions})
+	}
+	key := resourceCacheKey{gitCommitID: rhw.gitCommitID, project: scope.GetProject(), stage: scope.GetStage(), service: scope.GetService(), resource: scope.GetResource()}
+	if cached, ok := rhw.cache.get(key); ok {
+		return cached.resource, cached.err
+	}
+	resource, err := rhw.resourceHandler.GetResource(context.Background(), *v2Scope, apiv2.ResourcesGetResourceOptions{URIOptions: uriOptions})
+	// a resource missing at a commit stays missing, other errors might be temporary
+	if err == nil || errors.Is(err, apiv2.ResourceNotFoundError) {
+		rhw.cache.add(key, resource, err)
+	}
+	return copyResource(resource), err
+}
+
+// withGitCommitID adds the given git commit ID to the query of the resource uri, unless a commit ID has already been set
+func withGitCommitID(gitCommitID string) apiv2.URIOption {
+	return func(uri string) string {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return uri
+		}
+		query := parsed.Query()
+		if query.Get(gitCommitIDQueryParam) != "" {
+			return uri
+		}
+		query.Set(gitCommitIDQueryParam, gitCommitID)
+		parsed.RawQuery = query.Encode()
+		return parsed.String()
+	}
+}
+
+// eventKeptn is the IKeptn passed to an attempt of a task handler. It provides a ResourceHandler that fetches
+// resources at the git commit of the processed event and drops the events sent after the attempt has been abandoned
+type eventKeptn struct {
+	*Keptn
+	resourceHandler ResourceHandler
+	attempt         int
+}
+
+func (e *eventKeptn) GetResourceHandler() ResourceHandler {
+	return e.resourceHandler
+}
+
+func (e *eventKeptn) SendStartedEvent(parentEvent KeptnEvent) error {
+	return e.sendStartedEvent(parentEvent, e.attempt)
+}
+
+func (e *eventKeptn) SendStatusChangedEvent(parentEvent KeptnEvent, newEventData interface{}) error {
+	return e.sendStatusChangedEvent(parentEvent, newEventData, e.attempt)
+}
+
+func (e *eventKeptn) SendFinishedEvent(parentEvent KeptnEvent, newEventData interface{}) error {
+	return e.sendFinishedEvent(parentEvent, newEventData, e.attempt)
+}
+
+// eventHandle returns the IKeptn to be passed to the given attempt of the task handler processing the given event
+func (k *Keptn) eventHandle(event KeptnEvent, attempt int) IKeptn {
+	handle := &eventKeptn{Keptn: k, resourceHandler: k.resourceHandler, attempt: attempt}
+	if rhw, ok := k.resourceHandler.(*resourceHandlerWrapper); ok && !k.resourceOptions.IgnoreGitCommitID && event.GitCommitID != "" {
+		handle.resourceHandler = rhw.atCommit(event.GitCommitID)
+	}
+	return handle
+}
+
+// GetResourceHierarchically fetches the resource of the given scope on service level. If the resource does not exist on
+// service level, it is fetched on stage level and finally on project level. If the resource exists on none of these
+// levels, api.ResourceNotFoundError is returned
+func GetResourceHierarchically(handler ResourceHandler, scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+	scopes := []*api.ResourceScope{}
+	if scope.GetStage() != "" && scope.GetService() != "" {
+		scopes = append(scopes, api.NewResourceScope().Project(scope.GetProject()).Stage(scope.GetStage()).Service(scope.GetService()).Resource(scope.GetResource()))
+	}
+	if scope.GetStage() != "" {
+		scopes = append(scopes, api.NewResourceScope().Project(scope.GetProject()).Stage(scope.GetStage()).Resource(scope.GetResource()))
+	}
+	scopes = append(scopes, api.NewResourceScope().Project(scope.GetProject()).Resource(scope.GetResource()))
+
+	for _, s := range scopes {
+		resource, err := handler.GetResource(*s, options...)
+		if errors.Is(err, api.ResourceNotFoundError) || (err == nil && resource == nil) {
+			continue
+		}
+		return resource, err
+	}
+	return nil, api.ResourceNotFoundError
+}
+
+// GetEventResource fetches the resource with the given uri hierarchically for the project, stage and service of the given event
+func GetEventResource(handler ResourceHandler, event KeptnEvent, resourceURI string, options ...api.URIOption) (*models.Resource, error) {
+	eventData := keptnv2.EventData{}
+	if err := keptnv2.EventDataAs(models.KeptnContextExtendedCE(event), &eventData); err != nil {
+		return nil, err
+	}
+	scope := api.NewResourceScope().Project(eventData.Project).Stage(eventData.Stage).Service(eventData.Service).Resource(resourceURI)
+	return GetResourceHierarchically(handler, *scope, options...)
+}
+
+type resourceCacheKey struct {
+	gitCommitID string
+	project     string
+	stage       string
+	service     string
+	resource    string
+}
+
+type resourceCacheEntry struct {
+	key      resourceCacheKey
+	resource *models.Resource
+	err      error
+}
+
+// resourceCache keeps the most recently used resources
+type resourceCache struct {
+	mtx     sync.Mutex
+	size    int
+	order   *list.List
+	entries map[resourceCacheKey]*list.Element
+}
+
+func newResourceCache(size int) *resourceCache {
+	return &resourceCache{size: size, order: list.New(), entries: map[resourceCacheKey]*list.Element{}}
+}
+
+func (c *resourceCache) get(key resourceCacheKey) (resourceCacheEntry, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return resourceCacheEntry{}, false
+	}
+	c.order.MoveToFront(element)
+	entry := *element.Value.(*resourceCacheEntry)
+	entry.resource = copyResource(entry.resource)
+	return entry, true
+}
+
+func (c *resourceCache) add(key resourceCacheKey, resource *models.Resource, err error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&resourceCacheEntry{key: key, resource: copyResource(resource), err: err})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resourceCacheEntry).key)
+	}
+}
+
+// copyResource returns a copy of the given resource, so that callers modifying it do not alter cached resources
+func copyResource(resource *models.Resource) *models.Resource {
+	if resource == nil {
+		return nil
+	}
+	c := *resource
+	if resource.Metadata != nil {
+		metadata := *resource.Metadata
+		c.Metadata = &metadata
+	}
+	if resource.ResourceURI != nil {
+		resourceURI := *resource.ResourceURI
+		c.ResourceURI = &resourceURI
+	}
+	return &c
+}
@@ -0,0 +1,169 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+	utils_mock "github.com/keptn/go-utils/pkg/api/utils/v2/fake"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+// newRecordingResourcesMock returns a mock recording the uris of the requested resources.
+// Resources are only found at the levels contained in the given set
+func newRecordingResourcesMock(uris *[]string, levels map[string]bool) *utils_mock.ResourcesInterfaceMock {
+	return &utils_mock.ResourcesInterfaceMock{
+		GetResourceFunc: func(ctx context.Context, scope apiv2.ResourceScope, opts apiv2.ResourcesGetResourceOptions) (*models.Resource, error) {
+			uri := "http://resource-service" + scope.GetProjectPath() + scope.GetStagePath() + scope.GetServicePath() + scope.GetResourcePath()
+			for _, option := range opts.URIOptions {
+				uri = option(uri)
+			}
+			*uris = append(*uris, uri)
+			level := "project"
+			if scope.GetServicePath() != "" {
+				level = "service"
+			} else if scope.GetStagePath() != "" {
+				level = "stage"
+			}
+			if !levels[level] {
+				return nil, apiv2.ResourceNotFoundError
+			}
+			return &models.Resource{ResourceContent: level, ResourceURI: strutils.Stringp("config.yaml")}, nil
+		},
+	}
+}
+
+func Test_WhenTaskHandlerFetchesResources_ResourcesAreFetchedAtCommitOfEvent(t *testing.T) {
+	uris := []string{}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.resourceHandler = newResourceHandlerWrapper(newRecordingResourcesMock(&uris, map[string]bool{"service": true}), ResourceOptions{})
+	contents := []string{}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		for i := 0; i < 2; i++ {
+			resource, err := keptnHandle.GetResourceHandler().GetResource(*api.NewResourceScope().Project("prj").Stage("stg").Service("svc").Resource("dir/config.yaml"))
+			require.Nil(t, err)
+			contents = append(contents, resource.ResourceContent)
+			resource.ResourceContent = "modified"
+		}
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+
+	fakeKeptn.NewEvent(newTestTaskTriggeredEvent())
+
+	require.Equal(t, []string{"service", "service"}, contents)
+	require.Equal(t, []string{"http://resource-service/v1/project/prj/stage/stg/service/svc/resource/dir%2Fconfig.yaml?gitCommitID=mycommitid"}, uris)
+
+	// resources fetched outside of a task handler are not pinned to a commit
+	_, err := fakeKeptn.Keptn.GetResourceHandler().GetResource(*api.NewResourceScope().Project("prj").Stage("stg").Service("svc").Resource("dir/config.yaml"))
+	require.Nil(t, err)
+	require.Equal(t, "http://resource-service/v1/project/prj/stage/stg/service/svc/resource/dir%2Fconfig.yaml", uris[1])
+}
+
+func Test_WhenGitCommitIDIsIgnored_LatestResourcesAreFetched(t *testing.T) {
+	uris := []string{}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.Keptn.resourceOptions = ResourceOptions{IgnoreGitCommitID: true}
+	fakeKeptn.Keptn.resourceHandler = newResourceHandlerWrapper(newRecordingResourcesMock(&uris, map[string]bool{"service": true}), fakeKeptn.Keptn.resourceOptions)
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		for i := 0; i < 2; i++ {
+			_, err := keptnHandle.GetResourceHandler().GetResource(*api.NewResourceScope().Project("prj").Stage("stg").Service("svc").Resource("config.yaml"))
+			require.Nil(t, err)
+		}
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", taskHandler)
+
+	fakeKeptn.NewEvent(newTestTaskTriggeredEvent())
+
+	require.Equal(t, []string{
+		"http://resource-service/v1/project/prj/stage/stg/service/svc/resource/config.yaml",
+		"http://resource-service/v1/project/prj/stage/stg/service/svc/resource/config.yaml",
+	}, uris)
+}
+
+func Test_ResourceHandlerAtCommit_CachesResourcesPerCommit(t *testing.T) {
+	uris := []string{}
+	failing := true
+	mock := newRecordingResourcesMock(&uris, map[string]bool{"stage": true})
+	getResource := mock.GetResourceFunc
+	mock.GetResourceFunc = func(ctx context.Context, scope apiv2.ResourceScope, opts apiv2.ResourcesGetResourceOptions) (*models.Resource, error) {
+		// the first request for flaky.yaml fails before reaching the resource service
+		if scope.GetResourcePath() == "/resource/flaky.yaml" && failing {
+			failing = false
+			return nil, errors.New("unavailable")
+		}
+		return getResource(ctx, scope, opts)
+	}
+	handler := newResourceHandlerWrapper(mock, ResourceOptions{CacheSize: 2})
+	serviceScope := *api.NewResourceScope().Project("prj").Stage("stg").Service("svc").Resource("config.yaml")
+	stageScope := *api.NewResourceScope().Project("prj").Stage("stg").Resource("config.yaml")
+	flakyScope := *api.NewResourceScope().Project("prj").Stage("stg").Resource("flaky.yaml")
+
+	// missing resources are cached as well
+	for i := 0; i < 2; i++ {
+		_, err := handler.atCommit("commit-1").GetResource(serviceScope)
+		require.ErrorIs(t, err, api.ResourceNotFoundError)
+		_, err = handler.atCommit("commit-1").GetResource(stageScope)
+		require.Nil(t, err)
+	}
+	require.Len(t, uris, 2)
+
+	// other commits are fetched separately
+	_, err := handler.atCommit("commit-2").GetResource(stageScope)
+	require.Nil(t, err)
+	require.Len(t, uris, 3)
+	require.Contains(t, uris[2], "gitCommitID=commit-2")
+
+	// errors other than missing resources are not cached
+	_, err = handler.atCommit("commit-2").GetResource(flakyScope)
+	require.Error(t, err)
+	_, err = handler.atCommit("commit-2").GetResource(flakyScope)
+	require.Nil(t, err)
+	require.Len(t, uris, 4)
+
+	// the least recently used resource is evicted
+	_, err = handler.atCommit("commit-1").GetResource(stageScope)
+	require.Nil(t, err)
+	require.Len(t, uris, 5)
+}
+
+func Test_GetEventResource_ResolvesResourceHierarchically(t *testing.T) {
+	tests := []struct {
+		name        string
+		levels      map[string]bool
+		wantContent string
+		wantErr     error
+		wantURIs    int
+	}{
+		{name: "service level", levels: map[string]bool{"service": true, "stage": true, "project": true}, wantContent: "service", wantURIs: 1},
+		{name: "stage level", levels: map[string]bool{"stage": true, "project": true}, wantContent: "stage", wantURIs: 2},
+		{name: "project level", levels: map[string]bool{"project": true}, wantContent: "project", wantURIs: 3},
+		{name: "not found", levels: map[string]bool{}, wantErr: api.ResourceNotFoundError, wantURIs: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uris := []string{}
+			handler := newResourceHandlerWrapper(newRecordingResourcesMock(&uris, tt.levels), ResourceOptions{}).atCommit("mycommitid")
+			event := KeptnEvent(newTestTaskTriggeredEvent())
+			event.Data = v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc"}
+
+			resource, err := GetEventResource(handler, event, "config.yaml")
+
+			require.Len(t, uris, tt.wantURIs)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.wantContent, resource.ResourceContent)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+)
+
+const defaultRetryBackoffMultiplier = 2.0
+
+// RetryPolicy determines how often and when a task handler is executed again after it returned an error,
+// before the error is reported with the .finished event
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of executions of the task handler, including the first one.
+	// Values lower than 2 disable retries
+	MaxAttempts int
+	// InitialBackoff is the time waited before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff limits the time waited before a retry. Per default (zero value) the backoff is not limited
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff is increased by after every retry. Defaults to 2
+	Multiplier float64
+	// Jitter is the fraction, between 0 and 1, by which the backoff is randomly increased or decreased,
+	// so that retries of concurrently failing tasks are spread over time
+	Jitter float64
+	// Retryable determines whether the task handler shall be executed again after returning the given error.
+	// Per default, all errors are retried. Tasks whose context is done, e.g. because the sequence has been aborted,
+	// are never retried
+	Retryable func(err *Error) bool
+	// ReportAttempts determines whether a .status.changed event is sent for every failed attempt that is retried
+	ReportAttempts bool
+}
+
+// RetryIfErrorIs returns a predicate for RetryPolicy.Retryable retrying errors wrapping any of the given errors
+func RetryIfErrorIs(targets ...error) func(err *Error) bool {
+	return func(err *Error) bool {
+		for _, target := range targets {
+			if errors.Is(err.Err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// backoff returns the time to wait before the given retry, starting at 1 for the first retry
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultRetryBackoffMultiplier
+	}
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		backoff *= multiplier
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+func (p RetryPolicy) retryable(err *Error) bool {
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// executeTaskWithRetry executes the task handler and executes it again according to its retry policy, if it fails
+func (k *Keptn) executeTaskWithRetry(ctx context.Context, eventSender controlplane.EventSender, handler *taskEntry, event KeptnEvent, autoResponse bool) (interface{}, *Error) {
+	policy := handler.taskHandlerOpts.RetryPolicy
+	for attempt := 1; ; attempt++ {
+		result, err := k.executeTask(ctx, handler, event)
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(err) {
+			return result, err
+		}
+
+		backoff := policy.backoff(attempt)
+		k.logger.Warnf("Attempt %d of %d for event %s failed: %v. Retrying in %s", attempt, policy.MaxAttempts, event.ID, err.Err, backoff)
+		if policy.ReportAttempts && autoResponse {
+			k.reportRetry(eventSender, models.KeptnContextExtendedCE(event), fmt.Sprintf("attempt %d of %d failed: %s. Retrying in %s", attempt, policy.MaxAttempts, err.Message, backoff))
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// reportRetry sends a .status.changed event with the given message for the given event
+func (k *Keptn) reportRetry(eventSender controlplane.EventSender, event models.KeptnContextExtendedCE, message string) {
+	eventData := keptnv2.EventData{}
+	if err := keptnv2.EventDataAs(event, &eventData); err != nil {
+		k.logger.Errorf("Unable to decode event data of event %s: %v", event.ID, err)
+	}
+	eventData.Message = message
+	statusChangedEvent, err := keptnv2.CreateStatusChangedEvent(k.source, event, eventData)
+	if err != nil {
+		k.logger.Errorf("Unable to create '.status.changed' event: %v", err)
+		return
+	}
+	if err := eventSender(*statusChangedEvent); err != nil {
+		k.logger.Errorf("Unable to send '.status.changed' event: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+var errTransient = errors.New("transient")
+
+func newFailingTaskHandler(failures int, err error) (*TaskHandlerMock, *int) {
+	attempts := 0
+	return &TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		attempts++
+		if attempts <= failures {
+			return nil, &Error{StatusType: v0_2_0.StatusErrored, ResultType: v0_2_0.ResultFailed, Message: err.Error(), Err: err}
+		}
+		return FakeTaskData{}, nil
+	}}, &attempts
+}
+
+func Test_RetryPolicy_TaskHandlerIsRetriedUntilItSucceeds(t *testing.T) {
+	handler, attempts := newFailingTaskHandler(2, errTransient)
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", handler, TaskHandlerOptions{
+		RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, ReportAttempts: true},
+	})
+
+	require.NoError(t, fakeKeptn.NewEvent(newTestTaskTriggeredEvent()))
+
+	require.Equal(t, 3, *attempts)
+	fakeKeptn.AssertNumberOfEventSent(t, 4)
+	fakeKeptn.AssertSentEventType(t, 0, "sh.keptn.event.faketask.started")
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.status.changed")
+	fakeKeptn.AssertSentEventType(t, 2, "sh.keptn.event.faketask.status.changed")
+	fakeKeptn.AssertSentEventType(t, 3, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEventStatus(t, 3, v0_2_0.StatusSucceeded)
+}
+
+func Test_RetryPolicy_FailureIsReportedWhenAttemptsAreExhausted(t *testing.T) {
+	handler, attempts := newFailingTaskHandler(5, errTransient)
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", handler, TaskHandlerOptions{
+		RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+
+	require.NoError(t, fakeKeptn.NewEvent(newTestTaskTriggeredEvent()))
+
+	require.Equal(t, 3, *attempts)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventType(t, 1, "sh.keptn.event.faketask.finished")
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusErrored)
+}
+
+func Test_RetryPolicy_NonRetryableErrorsAreNotRetried(t *testing.T) {
+	handler, attempts := newFailingTaskHandler(1, errors.New("permanent"))
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.faketask.triggered", handler, TaskHandlerOptions{
+		RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Retryable: RetryIfErrorIs(errTransient)},
+	})
+
+	require.NoError(t, fakeKeptn.NewEvent(newTestTaskTriggeredEvent()))
+
+	require.Equal(t, 1, *attempts)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+	fakeKeptn.AssertSentEventStatus(t, 1, v0_2_0.StatusErrored)
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	require.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	require.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	require.Equal(t, 300*time.Millisecond, policy.backoff(3))
+
+	policy = RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 3, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		backoff := policy.backoff(2)
+		require.GreaterOrEqual(t, backoff, 150*time.Millisecond)
+		require.LessOrEqual(t, backoff, 450*time.Millisecond)
+	}
+}
@@ -0,0 +1,258 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+)
+
+// ErrTaskAborted is the cause of the context passed to a ContextTaskHandler when the
+// sequence of the processed event has been aborted or the task has been invalidated
+var ErrTaskAborted = errors.New("task aborted")
+
+type runningTask struct {
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	event     models.KeptnContextExtendedCE
+	startedAt time.Time
+	// finished is set as soon as a .finished event has been sent in response to the event
+	finished bool
+	// abandoned is set if the task has been given up during the shutdown of the sdk
+	abandoned bool
+	// attempt is the number of the current execution of the task handler
+	attempt int
+}
+
+// sdkAttempt is passed to guardSender for the events sent by the sdk itself rather than by a task handler.
+// These events are only dropped once the task has been abandoned during shutdown
+const sdkAttempt = -1
+
+// InFlightTask describes an event that is currently being processed by a task handler or waiting for a free worker
+type InFlightTask struct {
+	Event     KeptnEvent `json:"-"`
+	EventID   string     `json:"eventID"`
+	EventType string     `json:"eventType"`
+	StartedAt time.Time  `json:"startedAt"`
+}
+
+type runningTasks struct {
+	sync.RWMutex
+	// entries holds the currently running tasks, grouped by keptn context and event ID
+	entries map[string]map[string]*runningTask
+	// abandonedAttempts holds the executions of task handlers that exceeded their timeout but did not return yet, by event
+	abandonedAttempts map[string]map[int]struct{}
+}
+
+func newRunningTasks() *runningTasks {
+	return &runningTasks{
+		entries:           make(map[string]map[string]*runningTask),
+		abandonedAttempts: make(map[string]map[int]struct{}),
+	}
+}
+
+func attemptKey(event models.KeptnContextExtendedCE) string {
+	return event.Shkeptncontext + "/" + event.ID
+}
+
+// detachedContext carries the values of its parent context, but is not cancelled together with it
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+// Add derives a new cancellable context for the given event from the values of the given parent context.
+// The context is not cancelled together with the parent, e.g. when the shutdown signal is received, but only
+// via Cancel or Abandon, so that running tasks can finish while the sdk is draining.
+// The returned function must be called as soon as the task is done
+func (r *runningTasks) Add(parent context.Context, event models.KeptnContextExtendedCE) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(detachedContext{parent})
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.entries[event.Shkeptncontext]; !ok {
+		r.entries[event.Shkeptncontext] = make(map[string]*runningTask)
+	}
+	r.entries[event.Shkeptncontext][event.ID] = &runningTask{ctx: ctx, cancel: cancel, event: event, startedAt: time.Now()}
+
+	return ctx, func() {
+		r.remove(event)
+		cancel(nil)
+	}
+}
+
+// Get returns the context of the running task for the given event
+func (r *runningTasks) Get(event models.KeptnContextExtendedCE) (context.Context, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	task, ok := r.entries[event.Shkeptncontext][event.ID]
+	if !ok {
+		return nil, false
+	}
+	return task.ctx, true
+}
+
+// List returns the currently running tasks, ordered by the point in time they have been started
+func (r *runningTasks) List() []InFlightTask {
+	r.RLock()
+	defer r.RUnlock()
+	tasks := []InFlightTask{}
+	for _, byID := range r.entries {
+		for _, task := range byID {
+			tasks = append(tasks, InFlightTask{
+				Event:     KeptnEvent(task.event),
+				EventID:   task.event.ID,
+				EventType: *task.event.Type,
+				StartedAt: task.startedAt,
+			})
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartedAt.Before(tasks[j].StartedAt)
+	})
+	return tasks
+}
+
+// Abandon cancels the contexts of all running tasks with the given cause and marks them as abandoned,
+// so that no further .finished events are sent on their behalf. The tasks that have not sent a
+// .finished event yet are returned
+func (r *runningTasks) Abandon(cause error) []*runningTask {
+	r.Lock()
+	defer r.Unlock()
+	unfinished := []*runningTask{}
+	for _, byID := range r.entries {
+		for _, task := range byID {
+			task.abandoned = true
+			task.cancel(cause)
+			if !task.finished {
+				unfinished = append(unfinished, task)
+			}
+		}
+	}
+	return unfinished
+}
+
+// Abandoned checks whether the running task for the given event has been abandoned
+func (r *runningTasks) Abandoned(event models.KeptnContextExtendedCE) bool {
+	r.RLock()
+	defer r.RUnlock()
+	task, ok := r.entries[event.Shkeptncontext][event.ID]
+	return ok && task.abandoned
+}
+
+// BeginAttempt records that the task handler is executed once more for the given event and returns the number of the attempt
+func (r *runningTasks) BeginAttempt(event models.KeptnContextExtendedCE) int {
+	r.Lock()
+	defer r.Unlock()
+	task, ok := r.entries[event.Shkeptncontext][event.ID]
+	if !ok {
+		return 0
+	}
+	task.attempt++
+	return task.attempt
+}
+
+// AbandonAttempt marks the given execution of the task handler for the given event as abandoned after it exceeded
+// its timeout, so that the events it sends afterwards are dropped. The returned function must be called as soon as
+// the task handler returned
+func (r *runningTasks) AbandonAttempt(event models.KeptnContextExtendedCE, attempt int) func() {
+	key := attemptKey(event)
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.abandonedAttempts[key]; !ok {
+		r.abandonedAttempts[key] = make(map[int]struct{})
+	}
+	r.abandonedAttempts[key][attempt] = struct{}{}
+
+	return func() {
+		r.Lock()
+		defer r.Unlock()
+		delete(r.abandonedAttempts[key], attempt)
+		if len(r.abandonedAttempts[key]) == 0 {
+			delete(r.abandonedAttempts, key)
+		}
+	}
+}
+
+// isAbandonedAttempt checks whether events sent on behalf of the given attempt for the given event have to be dropped.
+// Events sent via the Keptn instance itself (attempt 0) are dropped while any attempt for the event is abandoned
+func (r *runningTasks) isAbandonedAttempt(event models.KeptnContextExtendedCE, attempt int) bool {
+	abandoned := r.abandonedAttempts[attemptKey(event)]
+	if attempt == 0 {
+		return len(abandoned) > 0
+	}
+	_, ok := abandoned[attempt]
+	return ok
+}
+
+// guardSender returns an EventSender dropping the events of the given task once the task has been abandoned.
+// Events sent by a task handler on behalf of the given attempt are also dropped once the attempt has been abandoned
+// after exceeding its timeout, see isAbandonedAttempt
+func (r *runningTasks) guardSender(event models.KeptnContextExtendedCE, attempt int, sender controlplane.EventSender) controlplane.EventSender {
+	return func(ce models.KeptnContextExtendedCE) error {
+		r.Lock()
+		task, ok := r.entries[event.Shkeptncontext][event.ID]
+		if ok && task.abandoned {
+			r.Unlock()
+			return fmt.Errorf("task for event %s has been abandoned during shutdown", event.ID)
+		}
+		if attempt != sdkAttempt && r.isAbandonedAttempt(event, attempt) {
+			r.Unlock()
+			return fmt.Errorf("task handler for event %s has been abandoned after exceeding its timeout", event.ID)
+		}
+		if ok && ce.Type != nil && keptnv2.IsFinishedEventType(*ce.Type) {
+			task.finished = true
+		}
+		r.Unlock()
+		return sender(ce)
+	}
+}
+
+// Cancel cancels the contexts of all running tasks belonging to the given keptn context
+func (r *runningTasks) Cancel(keptnContext string, cause error) int {
+	r.RLock()
+	defer r.RUnlock()
+	for _, task := range r.entries[keptnContext] {
+		task.cancel(cause)
+	}
+	return len(r.entries[keptnContext])
+}
+
+func (r *runningTasks) remove(event models.KeptnContextExtendedCE) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.entries[event.Shkeptncontext], event.ID)
+	if len(r.entries[event.Shkeptncontext]) == 0 {
+		delete(r.entries, event.Shkeptncontext)
+	}
+}
+
+// isAbortSignal checks whether the given event signals that running tasks of the same keptn context shall be cancelled.
+// This is the case for .invalidated events as well as for sequence .finished events with status "aborted"
+func isAbortSignal(event models.KeptnContextExtendedCE) bool {
+	kind, err := keptnv2.ParseEventKind(*event.Type)
+	if err != nil {
+		return false
+	}
+	if kind == "invalidated" {
+		return true
+	}
+	if keptnv2.IsSequenceEventType(*event.Type) && kind == "finished" {
+		eventData := keptnv2.EventData{}
+		if err := keptnv2.EventDataAs(event, &eventData); err != nil {
+			return false
+		}
+		return eventData.Status == keptnv2.StatusAborted
+	}
+	return false
+}
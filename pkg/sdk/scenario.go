@@ -0,0 +1,400 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateScenariosEnvVar is the environment variable that makes RunScenario write the events sent while running a
+// scenario into the expected events of its fixture file, instead of asserting on them
+const UpdateScenariosEnvVar = "KEPTN_UPDATE_SCENARIOS"
+
+// Scenario describes the events passed to a FakeKeptn, the resources, secrets and previous events available to the
+// task handlers, and the events expected to be sent in response. Scenarios are loaded from YAML or JSON fixture files.
+//
+// Of the Keptn API, only the GetEvents and GetEventsWithRetry methods of the v2 events API are faked, returning the
+// PreviousEvents. All other API calls are passed to the API sets configured via FakeKeptn.SetAPI and FakeKeptn.SetAPIV2,
+// so task handlers using further parts of the Keptn API need to be tested by providing them before running the scenario
+type Scenario struct {
+	// Name is used as name of the subtest running the scenario. Defaults to the name of the fixture file
+	Name string `json:"name,omitempty"`
+	// Input are the events passed to the FakeKeptn, one after the other
+	Input []models.KeptnContextExtendedCE `json:"input"`
+	// Resources are returned by the resource handler
+	Resources []ScenarioResource `json:"resources,omitempty"`
+	// Secrets map secret names to their keys and values returned by IKeptn.GetSecret
+	Secrets map[string]map[string]string `json:"secrets,omitempty"`
+	// PreviousEvents are returned by the events API, e.g. the .finished events read via IKeptn.GetTaskResult
+	PreviousEvents []models.KeptnContextExtendedCE `json:"previousEvents,omitempty"`
+	// Expected are the events expected to be sent, in the order they are sent
+	Expected []ExpectedEvent `json:"expected"`
+
+	path string
+}
+
+// ScenarioResource is a resource returned by the resource handler while running a scenario.
+// Project, stage and service only need to be set if the resource is supposed to be returned for a specific scope
+type ScenarioResource struct {
+	Project string `json:"project,omitempty"`
+	Stage   string `json:"stage,omitempty"`
+	Service string `json:"service,omitempty"`
+	URI     string `json:"uri"`
+	Content string `json:"content"`
+}
+
+// ExpectedEvent describes an event expected to be sent while running a scenario. Only the set properties are compared.
+// Data matches if all contained fields are contained in the data of the sent event with the same values
+type ExpectedEvent struct {
+	Type   string                 `json:"type" yaml:"type"`
+	Status string                 `json:"status,omitempty" yaml:"status,omitempty"`
+	Result string                 `json:"result,omitempty" yaml:"result,omitempty"`
+	Data   map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// LoadScenario reads the scenario from the given YAML or JSON file
+func LoadScenario(path string) (*Scenario, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("could not read scenario: %w", err)
+	}
+	// the fixture is converted to JSON, so that the json tags of the event model apply to YAML fixtures as well
+	var raw interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("could not decode scenario %s: %w", path, err)
+	}
+	jsonContent, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode scenario %s: %w", path, err)
+	}
+	scenario := &Scenario{}
+	if err := json.Unmarshal(jsonContent, scenario); err != nil {
+		return nil, fmt.Errorf("could not decode scenario %s: %w", path, err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	scenario.path = path
+	return scenario, nil
+}
+
+// RunScenarios runs the scenarios of all fixture files matching the given glob pattern as subtests.
+// Every scenario is run using a new FakeKeptn created by the given function, which is expected to register the task handlers
+func RunScenarios(t *testing.T, pattern string, newFakeKeptn func() *FakeKeptn) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatalf("invalid scenario pattern %s: %v", pattern, err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no scenario found matching %s", pattern)
+	}
+	for _, path := range paths {
+		scenario, err := LoadScenario(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(scenario.Name, func(t *testing.T) {
+			newFakeKeptn().RunScenario(t, scenario)
+		})
+	}
+}
+
+// RunScenario passes the input events of the given scenario to the FakeKeptn and asserts that the expected events have
+// been sent. Resources, secrets and events not contained in the scenario are not found.
+// If the environment variable UpdateScenariosEnvVar is set, the sent events are written into the fixture file instead
+func (f *FakeKeptn) RunScenario(t *testing.T, scenario *Scenario) {
+	f.SetResourceHandler(scenarioResourceHandler{resources: scenario.Resources})
+	f.SetSecretProviders(StaticSecretProvider(scenario.Secrets))
+	f.SetAPIV2(scenarioKeptnInterfaceV2{KeptnInterface: f.Keptn.apiV2, events: scenarioEventsAPI{events: scenario.PreviousEvents}})
+	for _, event := range scenario.Input {
+		if err := f.NewEvent(event); err != nil {
+			t.Fatalf("unable to process event %s: %v", event.ID, err)
+		}
+	}
+
+	if os.Getenv(UpdateScenariosEnvVar) != "" {
+		if err := scenario.updateExpected(f.SentEvents); err != nil {
+			t.Fatalf("unable to update scenario %s: %v", scenario.Name, err)
+		}
+		t.Logf("updated expected events of scenario %s", scenario.path)
+		return
+	}
+	if diff := diffEvents(scenario.Expected, f.SentEvents); diff != "" {
+		t.Errorf("sent events of scenario %s do not match the expected ones:\n%s\nSet %s to update the expected events of the fixture file", scenario.Name, diff, UpdateScenariosEnvVar)
+	}
+}
+
+// diffEvents returns a readable description of the differences between the expected and the sent events, or an empty string
+func diffEvents(expected []ExpectedEvent, sent []models.KeptnContextExtendedCE) string {
+	lines := []string{}
+	if len(expected) != len(sent) {
+		lines = append(lines, fmt.Sprintf("  expected %d events, got %d", len(expected), len(sent)))
+	}
+	for i := 0; i < len(expected) || i < len(sent); i++ {
+		switch {
+		case i >= len(sent):
+			lines = append(lines, fmt.Sprintf("  event %d: expected %s, got none", i, expected[i].Type))
+		case i >= len(expected):
+			lines = append(lines, fmt.Sprintf("  event %d: unexpected %s", i, eventType(sent[i])))
+		default:
+			for _, mismatch := range matchEvent(expected[i], sent[i]) {
+				lines = append(lines, fmt.Sprintf("  event %d (%s): %s", i, eventType(sent[i]), mismatch))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func eventType(event models.KeptnContextExtendedCE) string {
+	if event.Type == nil {
+		return "<no type>"
+	}
+	return *event.Type
+}
+
+// matchEvent returns the differences between the set properties of the expected event and the sent event
+func matchEvent(expected ExpectedEvent, sent models.KeptnContextExtendedCE) []string {
+	mismatches := []string{}
+	if expected.Type != "" && expected.Type != eventType(sent) {
+		mismatches = append(mismatches, fmt.Sprintf("type: expected %q, got %q", expected.Type, eventType(sent)))
+	}
+	eventData := v0_2_0.EventData{}
+	_ = v0_2_0.EventDataAs(sent, &eventData)
+	if expected.Status != "" && expected.Status != string(eventData.Status) {
+		mismatches = append(mismatches, fmt.Sprintf("status: expected %q, got %q", expected.Status, eventData.Status))
+	}
+	if expected.Result != "" && expected.Result != string(eventData.Result) {
+		mismatches = append(mismatches, fmt.Sprintf("result: expected %q, got %q", expected.Result, eventData.Result))
+	}
+	if expected.Data != nil {
+		var data interface{}
+		if err := v0_2_0.Decode(sent.Data, &data); err != nil {
+			return append(mismatches, fmt.Sprintf("data: unable to decode: %v", err))
+		}
+		var expectedData interface{}
+		if err := v0_2_0.Decode(expected.Data, &expectedData); err != nil {
+			return append(mismatches, fmt.Sprintf("data: unable to decode expected data: %v", err))
+		}
+		mismatches = append(mismatches, matchPartially("data", expectedData, data)...)
+	}
+	return mismatches
+}
+
+// matchPartially compares the given JSON values. Objects match if the actual object contains all fields of the expected
+// one with matching values, arrays match if they have the same length and matching elements
+func matchPartially(path string, expected interface{}, actual interface{}) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %s", path, formatValue(actual))}
+		}
+		keys := make([]string, 0, len(e))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		mismatches := []string{}
+		for _, key := range keys {
+			value, ok := a[key]
+			if !ok {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s: expected %s, got nothing", path, key, formatValue(e[key])))
+				continue
+			}
+			mismatches = append(mismatches, matchPartially(path+"."+key, e[key], value)...)
+		}
+		return mismatches
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, formatValue(expected), formatValue(actual))}
+		}
+		mismatches := []string{}
+		for i := range e {
+			mismatches = append(mismatches, matchPartially(fmt.Sprintf("%s[%d]", path, i), e[i], a[i])...)
+		}
+		return mismatches
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, formatValue(expected), formatValue(actual))}
+		}
+		return nil
+	}
+}
+
+func formatValue(v interface{}) string {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(content)
+}
+
+// updateExpected replaces the expected events in the fixture file of the scenario with the given events.
+// In YAML files, the remaining content including comments is kept as it is
+func (s *Scenario) updateExpected(sent []models.KeptnContextExtendedCE) error {
+	expected := make([]ExpectedEvent, 0, len(sent))
+	for _, event := range sent {
+		eventData := v0_2_0.EventData{}
+		_ = v0_2_0.EventDataAs(event, &eventData)
+		data := map[string]interface{}{}
+		if err := v0_2_0.Decode(event.Data, &data); err != nil {
+			return fmt.Errorf("could not decode data of event %s: %w", event.ID, err)
+		}
+		// status and result are compared via the dedicated fields of the expected event
+		delete(data, "status")
+		delete(data, "result")
+		expected = append(expected, ExpectedEvent{
+			Type:   eventType(event),
+			Status: string(eventData.Status),
+			Result: string(eventData.Result),
+			Data:   data,
+		})
+	}
+	s.Expected = expected
+
+	content, err := os.ReadFile(filepath.Clean(s.path))
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(s.path), ".json") {
+		content, err = replaceExpectedJSON(content, expected)
+	} else {
+		content, err = replaceExpectedYAML(content, expected)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, content, 0600)
+}
+
+func replaceExpectedJSON(content []byte, expected []ExpectedEvent) ([]byte, error) {
+	fixture := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		return nil, err
+	}
+	expectedContent, err := json.Marshal(expected)
+	if err != nil {
+		return nil, err
+	}
+	fixture["expected"] = expectedContent
+	updated, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(updated, '\n'), nil
+}
+
+func replaceExpectedYAML(content []byte, expected []ExpectedEvent) ([]byte, error) {
+	document := yaml.Node{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("scenario is not a mapping")
+	}
+	expectedNode := &yaml.Node{}
+	if err := expectedNode.Encode(expected); err != nil {
+		return nil, err
+	}
+	mapping := document.Content[0]
+	replaced := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == "expected" {
+			mapping.Content[i+1] = expectedNode
+			replaced = true
+		}
+	}
+	if !replaced {
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "expected"}, expectedNode)
+	}
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// scenarioResourceHandler returns the resources of a scenario
+type scenarioResourceHandler struct {
+	resources []ScenarioResource
+}
+
+func (s scenarioResourceHandler) GetResource(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+	for _, resource := range s.resources {
+		if resource.URI != scope.GetResource() ||
+			(resource.Project != "" && resource.Project != scope.GetProject()) ||
+			(resource.Stage != "" && resource.Stage != scope.GetStage()) ||
+			(resource.Service != "" && resource.Service != scope.GetService()) {
+			continue
+		}
+		uri := resource.URI
+		return &models.Resource{
+			Metadata:        &models.Version{Version: "CommitID"},
+			ResourceContent: resource.Content,
+			ResourceURI:     &uri,
+		}, nil
+	}
+	return nil, api.ResourceNotFoundError
+}
+
+// scenarioKeptnInterfaceV2 provides the events API returning the previous events of a scenario
+type scenarioKeptnInterfaceV2 struct {
+	apiv2.KeptnInterface
+	events scenarioEventsAPI
+}
+
+func (s scenarioKeptnInterfaceV2) Events() apiv2.EventsInterface {
+	return s.events
+}
+
+// scenarioEventsAPI returns the previous events of a scenario matching the given filter
+type scenarioEventsAPI struct {
+	events []models.KeptnContextExtendedCE
+}
+
+func (s scenarioEventsAPI) GetEvents(ctx context.Context, filter *apiv2.EventFilter, opts apiv2.EventsGetEventsOptions) ([]*models.KeptnContextExtendedCE, *models.Error) {
+	matching := []*models.KeptnContextExtendedCE{}
+	for i := range s.events {
+		event := s.events[i]
+		eventData := v0_2_0.EventData{}
+		_ = v0_2_0.EventDataAs(event, &eventData)
+		if (filter.EventType != "" && filter.EventType != eventType(event)) ||
+			(filter.KeptnContext != "" && filter.KeptnContext != event.Shkeptncontext) ||
+			(filter.EventID != "" && filter.EventID != event.ID) ||
+			(filter.Project != "" && filter.Project != eventData.Project) ||
+			(filter.Stage != "" && filter.Stage != eventData.Stage) ||
+			(filter.Service != "" && filter.Service != eventData.Service) {
+			continue
+		}
+		matching = append(matching, &event)
+	}
+	return matching, nil
+}
+
+func (s scenarioEventsAPI) GetEventsWithRetry(ctx context.Context, filter *apiv2.EventFilter, maxRetries int, retrySleepTime time.Duration, opts apiv2.EventsGetEventsWithRetryOptions) ([]*models.KeptnContextExtendedCE, error) {
+	events, err := s.GetEvents(ctx, filter, apiv2.EventsGetEventsOptions{})
+	if err != nil {
+		return nil, err.ToError()
+	}
+	return events, nil
+}
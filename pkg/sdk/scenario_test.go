@@ -0,0 +1,121 @@
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+type greetingData struct {
+	Greeting struct {
+		Message string `json:"message"`
+		URL     string `json:"url"`
+	} `json:"greeting"`
+}
+
+// newGreetingFakeKeptn greets with the content of the greeting.txt resource of the service and the deployment URL
+func newGreetingFakeKeptn() *FakeKeptn {
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		failed := func(err error) *Error {
+			return &Error{StatusType: keptnv2.StatusErrored, ResultType: keptnv2.ResultFailed, Message: err.Error(), Err: err}
+		}
+		resource, err := keptnHandle.GetResourceHandler().GetResource(*api.NewResourceScope().Project("prj").Stage("stg").Service("svc").Resource("greeting.txt"))
+		if err != nil {
+			return nil, failed(err)
+		}
+		if _, err := keptnHandle.GetSecret("greeting-credentials", "token"); err != nil {
+			return nil, failed(err)
+		}
+		deployment, err := DeploymentResult(keptnHandle, event)
+		if err != nil {
+			return nil, failed(err)
+		}
+		data := greetingData{}
+		data.Greeting.Message = resource.ResourceContent
+		data.Greeting.URL = deployment.Deployment.DeploymentURIsPublic[0]
+		return data, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.AddTaskHandler("sh.keptn.event.greeting.triggered", taskHandler)
+	return fakeKeptn
+}
+
+func Test_RunScenarios(t *testing.T) {
+	RunScenarios(t, "testdata/scenarios/*", newGreetingFakeKeptn)
+}
+
+func Test_LoadScenario_FromYAMLAndJSON(t *testing.T) {
+	scenario, err := LoadScenario("testdata/scenarios/greeting.yaml")
+	require.Nil(t, err)
+	require.Equal(t, "greeting from resource", scenario.Name)
+	require.Equal(t, "sh.keptn.event.greeting.triggered", *scenario.Input[0].Type)
+	require.Equal(t, ScenarioResource{Project: "prj", Stage: "stg", Service: "svc", URI: "greeting.txt", Content: "Hello"}, scenario.Resources[0])
+	require.Equal(t, "s3cr3t-t0ken", scenario.Secrets["greeting-credentials"]["token"])
+	require.Len(t, scenario.Expected, 2)
+
+	scenario, err = LoadScenario("testdata/scenarios/missing-resource.json")
+	require.Nil(t, err)
+	require.Equal(t, "errored", scenario.Expected[1].Status)
+}
+
+func Test_DiffEvents_DescribesMismatches(t *testing.T) {
+	scenario, err := LoadScenario("testdata/scenarios/greeting.yaml")
+	require.Nil(t, err)
+	scenario.Expected = append(scenario.Expected, ExpectedEvent{Type: "sh.keptn.event.other.finished"})
+	fakeKeptn := newGreetingFakeKeptn()
+	fakeKeptn.SetResourceHandler(scenarioResourceHandler{})
+	fakeKeptn.SetSecretProviders(StaticSecretProvider(scenario.Secrets))
+	for _, event := range scenario.Input {
+		require.Nil(t, fakeKeptn.NewEvent(event))
+	}
+
+	diff := diffEvents(scenario.Expected, fakeKeptn.SentEvents)
+
+	require.Contains(t, diff, "expected 3 events, got 2")
+	require.Contains(t, diff, "event 1 (sh.keptn.event.greeting.finished): status: expected \"succeeded\", got \"errored\"")
+	require.Contains(t, diff, "event 1 (sh.keptn.event.greeting.finished): data.greeting: expected {\"message\":\"Hello\",\"url\":\"http://svc.prj-stg\"}, got nothing")
+	require.Contains(t, diff, "event 2: expected sh.keptn.event.other.finished, got none")
+}
+
+func Test_MatchPartially_IgnoresAdditionalFields(t *testing.T) {
+	actual := map[string]interface{}{"a": "1", "b": []interface{}{float64(1), map[string]interface{}{"c": "2", "d": "3"}}}
+
+	require.Empty(t, matchPartially("data", map[string]interface{}{"b": []interface{}{float64(1), map[string]interface{}{"d": "3"}}}, actual))
+	require.Equal(t, []string{`data.b[1].d: expected "4", got "3"`}, matchPartially("data", map[string]interface{}{"b": []interface{}{float64(1), map[string]interface{}{"d": "4"}}}, actual))
+	require.Equal(t, []string{`data.b: expected [1], got [1,{"c":"2","d":"3"}]`}, matchPartially("data", map[string]interface{}{"b": []interface{}{float64(1)}}, actual))
+}
+
+func Test_WhenUpdatingScenarios_ExpectedEventsAreWrittenToFixture(t *testing.T) {
+	for _, name := range []string{"greeting.yaml", "missing-resource.json"} {
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("testdata/scenarios", name))
+			require.Nil(t, err)
+			path := filepath.Join(t.TempDir(), name)
+			require.Nil(t, os.WriteFile(path, content, 0600))
+			scenario, err := LoadScenario(path)
+			require.Nil(t, err)
+			scenario.Expected = nil
+
+			t.Setenv(UpdateScenariosEnvVar, "true")
+			newGreetingFakeKeptn().RunScenario(t, scenario)
+
+			updated, err := LoadScenario(path)
+			require.Nil(t, err)
+			require.Equal(t, scenario.Name, updated.Name)
+			require.Len(t, updated.Expected, 2)
+			fakeKeptn := newGreetingFakeKeptn()
+			fakeKeptn.SetResourceHandler(scenarioResourceHandler{resources: updated.Resources})
+			fakeKeptn.SetSecretProviders(StaticSecretProvider(updated.Secrets))
+			fakeKeptn.SetAPIV2(scenarioKeptnInterfaceV2{events: scenarioEventsAPI{events: updated.PreviousEvents}})
+			for _, event := range updated.Input {
+				require.Nil(t, fakeKeptn.NewEvent(event))
+			}
+			require.Empty(t, diffEvents(updated.Expected, fakeKeptn.SentEvents))
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+)
+
+const (
+	// DefaultSecretsDirectory is the directory mounted Kubernetes secrets are read from, unless configured otherwise
+	DefaultSecretsDirectory = "/etc/keptn/secrets"
+	redactedSecret          = "[REDACTED]"
+	// minRedactedSecretLength is the minimum length of secret values redacted in logs. Shorter values would garble the logs
+	minRedactedSecretLength = 4
+	// secretServiceTimeout limits the time waited for the secret-service to list the secrets
+	secretServiceTimeout = 5 * time.Second
+	// secretServiceCacheDuration is the duration the metadata listed by the secret-service is reused for
+	secretServiceCacheDuration = time.Minute
+)
+
+// ErrSecretNotFound is returned if a secret or the requested key of the secret is unknown
+var ErrSecretNotFound = errors.New("secret not found")
+
+// ErrSecretNotMounted is returned if a secret is managed by the secret-service, but has neither been
+// mounted into the service nor exposed as environment variable
+var ErrSecretNotMounted = errors.New("secret not mounted")
+
+// SecretProvider looks up the values of secrets
+type SecretProvider interface {
+	// GetSecret returns the value stored under the given key of the secret with the given name.
+	// If the provider does not know the secret or the key, an error wrapping ErrSecretNotFound is returned
+	GetSecret(name string, key string) (string, error)
+}
+
+// SecretSource is one of the built-in sources secrets are looked up from
+type SecretSource int
+
+const (
+	// SecretSourceFiles reads secrets from files named <directory>/<secret name>/<key>, as created when
+	// mounting a Kubernetes secret as volume at <directory>/<secret name>
+	SecretSourceFiles SecretSource = iota
+	// SecretSourceEnv reads secrets from environment variables named <prefix><SECRET_NAME>_<KEY>,
+	// with all characters other than letters and digits replaced by underscores
+	SecretSourceEnv
+	// SecretSourceSecretService checks the metadata of the secrets managed by the Keptn secret-service.
+	// Since the secret-service does not expose secret values, it reports secrets that exist but have not
+	// been made available to the service via files or environment variables
+	SecretSourceSecretService
+)
+
+// SecretOptions configures where IKeptn.GetSecret looks up secrets
+type SecretOptions struct {
+	// Precedence is the order the built-in secret sources are consulted in.
+	// Defaults to files, environment variables, secret-service
+	Precedence []SecretSource
+	// Directory is the directory mounted secrets are read from. Defaults to DefaultSecretsDirectory
+	Directory string
+	// EnvPrefix is prepended to the names of the environment variables secrets are read from
+	EnvPrefix string
+	// Providers are consulted in the given order before the built-in secret sources
+	Providers []SecretProvider
+}
+
+// WithSecrets configures where secrets are looked up by IKeptn.GetSecret
+func WithSecrets(options SecretOptions) KeptnOption {
+	return func(k *Keptn) {
+		k.secretOptions = options
+	}
+}
+
+// initSecrets creates the secret providers in the configured order of precedence
+func (k *Keptn) initSecrets() {
+	precedence := k.secretOptions.Precedence
+	if len(precedence) == 0 {
+		precedence = []SecretSource{SecretSourceFiles, SecretSourceEnv, SecretSourceSecretService}
+	}
+	directory := k.secretOptions.Directory
+	if directory == "" {
+		directory = DefaultSecretsDirectory
+	}
+	providers := append([]SecretProvider{}, k.secretOptions.Providers...)
+	for _, source := range precedence {
+		switch source {
+		case SecretSourceFiles:
+			providers = append(providers, NewFileSecretProvider(directory))
+		case SecretSourceEnv:
+			providers = append(providers, NewEnvSecretProvider(k.secretOptions.EnvPrefix))
+		case SecretSourceSecretService:
+			providers = append(providers, NewSecretServiceProvider(k.apiV2.Secrets(), k.logger))
+		default:
+			k.logger.Warnf("Ignoring unknown secret source %d", source)
+		}
+	}
+	k.secretProviders = providers
+}
+
+// GetSecret looks up the value stored under the given key of the secret with the given name from the configured
+// secret providers, in order of their precedence. The value is redacted from the entries logged by the sdk
+func (k *Keptn) GetSecret(name string, key string) (string, error) {
+	for _, provider := range k.secretProviders {
+		value, err := provider.GetSecret(name, key)
+		if errors.Is(err, ErrSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		k.secretRedactor.add(value)
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: key %s of secret %s", ErrSecretNotFound, key, name)
+}
+
+// FileSecretProvider is a SecretProvider reading secrets mounted as files
+type FileSecretProvider struct {
+	directory string
+}
+
+// NewFileSecretProvider creates a FileSecretProvider reading the key of a secret from the file <directory>/<name>/<key>
+func NewFileSecretProvider(directory string) *FileSecretProvider {
+	return &FileSecretProvider{directory: filepath.Clean(directory)}
+}
+
+// GetSecret returns the content of the file <directory>/<name>/<key>
+func (f *FileSecretProvider) GetSecret(name string, key string) (string, error) {
+	if !isValidSecretPathElement(name) || !isValidSecretPathElement(key) {
+		return "", fmt.Errorf("%w: invalid secret name %q or key %q", ErrSecretNotFound, name, key)
+	}
+	content, err := os.ReadFile(filepath.Join(f.directory, name, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: key %s of secret %s", ErrSecretNotFound, key, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read key %s of secret %s: %w", key, name, err)
+	}
+	return string(content), nil
+}
+
+// isValidSecretPathElement checks that the given secret name or key cannot be used to read files outside the secrets directory
+func isValidSecretPathElement(element string) bool {
+	return element != "" && element != "." && element != ".." && !strings.ContainsAny(element, `/\`)
+}
+
+// EnvSecretProvider is a SecretProvider reading secrets from environment variables
+type EnvSecretProvider struct {
+	prefix string
+}
+
+// NewEnvSecretProvider creates an EnvSecretProvider reading the key of a secret from the environment variable
+// <prefix><SECRET_NAME>_<KEY>, e.g. GIT_CREDENTIALS_TOKEN for the key token of the secret git-credentials
+func NewEnvSecretProvider(prefix string) *EnvSecretProvider {
+	return &EnvSecretProvider{prefix: prefix}
+}
+
+// GetSecret returns the value of the environment variable of the given key of the given secret
+func (e *EnvSecretProvider) GetSecret(name string, key string) (string, error) {
+	value, ok := os.LookupEnv(e.prefix + envVarName(name) + "_" + envVarName(key))
+	if !ok {
+		return "", fmt.Errorf("%w: key %s of secret %s", ErrSecretNotFound, key, name)
+	}
+	return value, nil
+}
+
+func envVarName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(s))
+}
+
+// SecretServiceProvider is a SecretProvider checking the metadata of the secrets managed by the Keptn secret-service.
+// As the secret-service does not expose secret values, it never returns a value, but reports secrets that exist
+// without being available to the service. It is meant to be consulted after the providers that are able to read values.
+// The metadata is cached for a minute. If the secret-service cannot be reached, a warning is logged and the secret
+// is reported as not found
+type SecretServiceProvider struct {
+	secrets  apiv2.SecretsInterface
+	logger   Logger
+	mtx      sync.Mutex
+	response *models.GetSecretsResponse
+	expires  time.Time
+}
+
+// NewSecretServiceProvider creates a SecretServiceProvider using the given secrets API
+func NewSecretServiceProvider(secrets apiv2.SecretsInterface, logger Logger) *SecretServiceProvider {
+	return &SecretServiceProvider{secrets: secrets, logger: logger}
+}
+
+// GetSecret returns ErrSecretNotMounted if the secret-service manages the given key of the given secret and ErrSecretNotFound otherwise
+func (s *SecretServiceProvider) GetSecret(name string, key string) (string, error) {
+	response, err := s.getSecrets()
+	if err != nil {
+		s.logger.Warnf("Unable to check whether secret %s is managed by the secret-service: %v", name, err)
+		return "", fmt.Errorf("%w: key %s of secret %s, could not get secrets from secret-service: %v", ErrSecretNotFound, key, name, err)
+	}
+	if hasSecretKey(response, name, key) {
+		return "", fmt.Errorf("%w: key %s of secret %s is managed by the secret-service, but not available to the service", ErrSecretNotMounted, key, name)
+	}
+	return "", fmt.Errorf("%w: key %s of secret %s", ErrSecretNotFound, key, name)
+}
+
+// getSecrets returns the cached metadata of the secrets, or lists them from the secret-service once the cache expired
+func (s *SecretServiceProvider) getSecrets() (*models.GetSecretsResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.response != nil && time.Now().Before(s.expires) {
+		return s.response, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretServiceTimeout)
+	defer cancel()
+	response, err := s.secrets.GetSecrets(ctx, apiv2.SecretsGetSecretsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	s.response = response
+	s.expires = time.Now().Add(secretServiceCacheDuration)
+	return response, nil
+}
+
+func hasSecretKey(response *models.GetSecretsResponse, name string, key string) bool {
+	if response == nil {
+		return false
+	}
+	for _, secret := range response.Secrets {
+		if secret.Name == nil || *secret.Name != name {
+			continue
+		}
+		for _, k := range secret.Keys {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StaticSecretProvider is a SecretProvider returning the values of the contained secrets, mapping
+// secret names to their keys and values. It is meant to provide secrets to task handlers in tests
+type StaticSecretProvider map[string]map[string]string
+
+// GetSecret returns the value of the given key of the given secret
+func (s StaticSecretProvider) GetSecret(name string, key string) (string, error) {
+	value, ok := s[name][key]
+	if !ok {
+		return "", fmt.Errorf("%w: key %s of secret %s", ErrSecretNotFound, key, name)
+	}
+	return value, nil
+}
+
+// secretRedactor replaces the secret values returned by IKeptn.GetSecret in log messages
+type secretRedactor struct {
+	mtx sync.RWMutex
+	// values holds the secret values, longest first so that values containing other values are redacted entirely
+	values []string
+}
+
+func newSecretRedactor() *secretRedactor {
+	return &secretRedactor{}
+}
+
+func (r *secretRedactor) add(value string) {
+	if r == nil || len(value) < minRedactedSecretLength {
+		return
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, v := range r.values {
+		if v == value {
+			return
+		}
+	}
+	r.values = append(r.values, value)
+	sort.SliceStable(r.values, func(i, j int) bool {
+		return len(r.values[i]) > len(r.values[j])
+	})
+}
+
+func (r *secretRedactor) redact(message string) string {
+	if r == nil {
+		return message
+	}
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	for _, value := range r.values {
+		message = strings.ReplaceAll(message, value, redactedSecret)
+	}
+	return message
+}
+
+// redactingLogger is a StructuredLogger replacing secret values in the messages passed to the wrapped logger
+type redactingLogger struct {
+	logger   Logger
+	redactor *secretRedactor
+}
+
+func newRedactingLogger(logger Logger, redactor *secretRedactor) redactingLogger {
+	return redactingLogger{logger: logger, redactor: redactor}
+}
+
+func (r redactingLogger) WithFields(fields Fields) StructuredLogger {
+	return redactingLogger{logger: withFields(r.logger, fields), redactor: r.redactor}
+}
+
+func (r redactingLogger) Debug(v ...interface{}) {
+	r.logger.Debug(r.redactor.redact(fmt.Sprint(v...)))
+}
+
+func (r redactingLogger) Debugf(format string, v ...interface{}) {
+	r.logger.Debug(r.redactor.redact(fmt.Sprintf(format, v...)))
+}
+
+func (r redactingLogger) Info(v ...interface{}) {
+	r.logger.Info(r.redactor.redact(fmt.Sprint(v...)))
+}
+
+func (r redactingLogger) Infof(format string, v ...interface{}) {
+	r.logger.Info(r.redactor.redact(fmt.Sprintf(format, v...)))
+}
+
+func (r redactingLogger) Warn(v ...interface{}) {
+	r.logger.Warn(r.redactor.redact(fmt.Sprint(v...)))
+}
+
+func (r redactingLogger) Warnf(format string, v ...interface{}) {
+	r.logger.Warn(r.redactor.redact(fmt.Sprintf(format, v...)))
+}
+
+func (r redactingLogger) Error(v ...interface{}) {
+	r.logger.Error(r.redactor.redact(fmt.Sprint(v...)))
+}
+
+func (r redactingLogger) Errorf(format string, v ...interface{}) {
+	r.logger.Error(r.redactor.redact(fmt.Sprintf(format, v...)))
+}
+
+func (r redactingLogger) Fatal(v ...interface{}) {
+	r.logger.Fatal(r.redactor.redact(fmt.Sprint(v...)))
+}
+
+func (r redactingLogger) Fatalf(format string, v ...interface{}) {
+	r.logger.Fatal(r.redactor.redact(fmt.Sprintf(format, v...)))
+}
@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api_mock "github.com/keptn/go-utils/pkg/api/utils/fake"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+	utils_mock "github.com/keptn/go-utils/pkg/api/utils/v2/fake"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/stretchr/testify/require"
+)
+
+type secretsKeptnInterfaceV2 struct {
+	apiv2.KeptnInterface
+	secrets apiv2.SecretsInterface
+}
+
+func (s secretsKeptnInterfaceV2) Secrets() apiv2.SecretsInterface {
+	return s.secrets
+}
+
+func newSecretsTestKeptn(t *testing.T, options SecretOptions) *Keptn {
+	k := newKeptn("fake", Config{}, WithSecrets(options))
+	k.apiV2 = secretsKeptnInterfaceV2{secrets: &utils_mock.SecretsInterfaceMock{
+		GetSecretsFunc: func(ctx context.Context, opts apiv2.SecretsGetSecretsOptions) (*models.GetSecretsResponse, error) {
+			return &models.GetSecretsResponse{Secrets: []models.GetSecretResponseItem{
+				{SecretMetadata: models.SecretMetadata{Name: strutils.Stringp("unmounted"), Scope: strutils.Stringp("keptn-default")}, Keys: []string{"token"}},
+			}}, nil
+		},
+	}}
+	k.initSecrets()
+	return k
+}
+
+func writeSecretFile(t *testing.T, dir string, name string, key string, value string) {
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, name), 0700))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, name, key), []byte(value), 0600))
+}
+
+func Test_GetSecret_ConsultsSourcesInOrderOfPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeSecretFile(t, dir, "git-credentials", "token", "from-file")
+	writeSecretFile(t, dir, "file-only", "token", "file-only-value")
+	t.Setenv("TEST_GIT_CREDENTIALS_TOKEN", "from-env")
+
+	tests := []struct {
+		name       string
+		precedence []SecretSource
+		providers  []SecretProvider
+		want       string
+	}{
+		{name: "files before env vars", want: "from-file"},
+		{name: "env vars before files", precedence: []SecretSource{SecretSourceEnv, SecretSourceFiles}, want: "from-env"},
+		{name: "custom providers first", providers: []SecretProvider{StaticSecretProvider{"git-credentials": {"token": "static"}}}, want: "static"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := newSecretsTestKeptn(t, SecretOptions{Precedence: tt.precedence, Directory: dir, EnvPrefix: "TEST_", Providers: tt.providers})
+
+			value, err := k.GetSecret("git-credentials", "token")
+			require.Nil(t, err)
+			require.Equal(t, tt.want, value)
+
+			value, err = k.GetSecret("file-only", "token")
+			require.Nil(t, err)
+			require.Equal(t, "file-only-value", value)
+		})
+	}
+}
+
+func Test_GetSecret_ReportsMissingSecrets(t *testing.T) {
+	k := newSecretsTestKeptn(t, SecretOptions{Directory: t.TempDir()})
+
+	_, err := k.GetSecret("unmounted", "token")
+	require.ErrorIs(t, err, ErrSecretNotMounted)
+
+	_, err = k.GetSecret("unknown", "token")
+	require.ErrorIs(t, err, ErrSecretNotFound)
+
+	_, err = k.GetSecret("..", "passwd")
+	require.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func Test_SecretServiceProvider_CachesMetadataAndToleratesErrors(t *testing.T) {
+	calls := 0
+	unavailable := true
+	logger := &recordingLogger{}
+	provider := NewSecretServiceProvider(&utils_mock.SecretsInterfaceMock{
+		GetSecretsFunc: func(ctx context.Context, opts apiv2.SecretsGetSecretsOptions) (*models.GetSecretsResponse, error) {
+			calls++
+			_, hasDeadline := ctx.Deadline()
+			require.True(t, hasDeadline)
+			if unavailable {
+				return nil, errors.New("connection refused")
+			}
+			return &models.GetSecretsResponse{Secrets: []models.GetSecretResponseItem{
+				{SecretMetadata: models.SecretMetadata{Name: strutils.Stringp("unmounted")}, Keys: []string{"token"}},
+			}}, nil
+		},
+	}, logger)
+
+	_, err := provider.GetSecret("unmounted", "token")
+	require.ErrorIs(t, err, ErrSecretNotFound)
+	require.True(t, logger.contains("connection refused"))
+
+	unavailable = false
+	_, err = provider.GetSecret("unmounted", "token")
+	require.ErrorIs(t, err, ErrSecretNotMounted)
+	_, err = provider.GetSecret("unknown", "token")
+	require.ErrorIs(t, err, ErrSecretNotFound)
+	require.Equal(t, 2, calls)
+}
+
+func Test_WhenSecretIsRetrieved_ValueIsRedactedInLogs(t *testing.T) {
+	logger := &recordingLogger{}
+	logs := &api_mock.ILogHandlerMock{LogFunc: func(logs []models.LogEntry) {}}
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		token, err := keptnHandle.GetSecret("git-credentials", "token")
+		require.Nil(t, err)
+		keptnHandle.EventLogger(event).Infof("cloning with token %s", token)
+		return nil, &Error{Err: errors.New("authentication with s3cr3t-t0ken failed")}
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.IntegrationID = "my-integration"
+	fakeKeptn.SetAPI(logForwardingKeptnInterface{logs: logs})
+	fakeKeptn.SetLogForwarding(LogForwardingOptions{Level: LogLevelInfo})
+	fakeKeptn.SetSecretProviders(StaticSecretProvider{"git-credentials": {"token": "s3cr3t-t0ken"}})
+	fakeKeptn.Keptn.logger = newRedactingLogger(logger, fakeKeptn.Keptn.secretRedactor)
+	fakeKeptn.AddTaskHandler("sh.keptn.event.test.triggered", taskHandler)
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.test.triggered"))
+
+	require.True(t, logger.contains("cloning with token [REDACTED]"))
+	require.True(t, logger.contains("authentication with [REDACTED] failed"))
+	require.False(t, logger.contains("s3cr3t-t0ken"))
+	require.Len(t, logs.LogCalls(), 1)
+	require.Equal(t, "cloning with token [REDACTED]", logs.LogCalls()[0].Logs[0].Message)
+	require.Equal(t, logger, fakeKeptn.Keptn.Logger())
+}
@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/sdk/connector/eventmatcher"
+)
+
+// SubscriptionMode determines how the sdk computes the uniform subscriptions of the integration
+type SubscriptionMode int
+
+// duplicateDeliveryWindow is the time during which an event received again is considered to be delivered once
+// per matching subscription, e.g. for sh.keptn.event.deployment.triggered and sh.keptn.event.*.triggered
+const duplicateDeliveryWindow = 10 * time.Second
+
+// abortSignalEventTypes are the subscriptions needed to receive the events signalling that running and pending tasks
+// of a sequence shall be cancelled, i.e. task .invalidated events and sequence .finished events
+var abortSignalEventTypes = []string{"sh.keptn.event.*.invalidated", "sh.keptn.event.*.*.finished"}
+
+const (
+	// SubscriptionModePubSubTopic only subscribes to the event types listed in the PUBSUB_TOPIC environment variable.
+	// In every mode, the abort signals cancelling context aware task handlers and pending tasks are subscribed to
+	// as well if these are used
+	SubscriptionModePubSubTopic SubscriptionMode = iota
+	// SubscriptionModeRegistry derives the subscriptions from the registered task handlers and event observers
+	SubscriptionModeRegistry
+	// SubscriptionModeMerged subscribes to the event types listed in the PUBSUB_TOPIC environment variable
+	// as well as to the event types of the registered task handlers and event observers
+	SubscriptionModeMerged
+)
+
+// WithSubscriptionMode configures how the sdk computes the uniform subscriptions of the integration.
+// Events matching several of the subscriptions, e.g. because a task handler and an event observer have been
+// registered for overlapping event types, are only processed once.
+// Per default, SubscriptionModePubSubTopic is used
+func WithSubscriptionMode(mode SubscriptionMode) KeptnOption {
+	return func(k *Keptn) {
+		k.subscriptionMode = mode
+	}
+}
+
+// subscriptions computes the uniform subscriptions of the integration according to the configured SubscriptionMode
+func (k *Keptn) subscriptions() []models.EventSubscription {
+	return k.subscriptionsFor(k.subscriptionMode)
+}
+
+// subscriptionsFor computes the uniform subscriptions of the integration according to the given SubscriptionMode
+func (k *Keptn) subscriptionsFor(mode SubscriptionMode) []models.EventSubscription {
+	subscriptions := []models.EventSubscription{}
+	// the entries of PUBSUB_TOPIC already are NATS subjects, only the patterns of the registries need to be translated
+	if mode != SubscriptionModeRegistry && k.env.PubSubTopic != "" {
+		for _, s := range strings.Split(k.env.PubSubTopic, ",") {
+			subscriptions = appendSubscription(subscriptions, models.EventSubscription{Event: s})
+		}
+	}
+	if mode != SubscriptionModePubSubTopic {
+		if k.taskRegistry != nil {
+			for _, task := range k.taskRegistry.List() {
+				subscriptions = appendSubscription(subscriptions, models.EventSubscription{
+					Event:  natsSubject(task.eventType),
+					Filter: task.entry.taskHandlerOpts.SubscriptionFilter,
+				})
+			}
+		}
+		if k.observerRegistry != nil {
+			for _, eventType := range k.observerRegistry.EventTypes() {
+				subscriptions = appendSubscription(subscriptions, models.EventSubscription{Event: natsSubject(eventType)})
+			}
+		}
+	}
+	// context aware task handlers and pending tasks are cancelled by abort signals, which hence need to be received
+	// regardless of the subscription mode
+	if k.cancellable() {
+		for _, eventType := range abortSignalEventTypes {
+			subscriptions = appendSubscription(subscriptions, models.EventSubscription{Event: eventType})
+		}
+	}
+	return subscriptions
+}
+
+// cancellable checks whether context aware task handlers or pending tasks are used, which are cancelled by abort signals
+func (k *Keptn) cancellable() bool {
+	if k.pendingTasks != nil {
+		return true
+	}
+	if k.taskRegistry == nil {
+		return false
+	}
+	for _, task := range k.taskRegistry.List() {
+		if task.entry.contextTaskHandler != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// appendSubscription appends the given subscription unless an equal subscription is already contained
+func appendSubscription(subscriptions []models.EventSubscription, subscription models.EventSubscription) []models.EventSubscription {
+	for _, s := range subscriptions {
+		if s.Event == subscription.Event && reflect.DeepEqual(s.Filter, subscription.Filter) {
+			return subscriptions
+		}
+	}
+	return append(subscriptions, subscription)
+}
+
+// matchesSubscriptionFilter checks whether the given event matches the subscription filter of the given task handler.
+// Events can reach a task handler without matching its filter, e.g. via a subscription listed in PUBSUB_TOPIC
+func matchesSubscriptionFilter(entry *taskEntry, event models.KeptnContextExtendedCE) bool {
+	return eventmatcher.New(models.EventSubscription{Filter: entry.taskHandlerOpts.SubscriptionFilter}).Matches(event)
+}
+
+// deliveries keeps the IDs of the recently received events, since events matching several subscriptions are delivered
+// once per subscription by the event sources, but must only be processed once
+type deliveries struct {
+	mtx    sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time
+}
+
+func newDeliveries(window time.Duration) *deliveries {
+	return &deliveries{window: window, seen: map[string]time.Time{}}
+}
+
+// first reports whether the event with the given ID has not been received within the window yet
+func (d *deliveries) first(eventID string) bool {
+	if d == nil || eventID == "" {
+		return true
+	}
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	now := time.Now()
+	for id, receivedAt := range d.seen {
+		if now.Sub(receivedAt) > d.window {
+			delete(d.seen, id)
+		}
+	}
+	if _, ok := d.seen[eventID]; ok {
+		return false
+	}
+	d.seen[eventID] = now
+	return true
+}
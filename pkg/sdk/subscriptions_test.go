@@ -0,0 +1,122 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newSubscriptionTestKeptn(mode SubscriptionMode) *Keptn {
+	k := &Keptn{
+		env:              config.EnvConfig{PubSubTopic: "sh.keptn.event.deployment.triggered,sh.keptn.event.test.triggered"},
+		taskRegistry:     newTaskMap(),
+		observerRegistry: newObserverRegistry(),
+		subscriptionMode: mode,
+	}
+	WithTaskEventHandler("sh.keptn.event.test.triggered", &TaskHandlerMock{}, TaskHandlerOptions{})(k)
+	WithTaskEventHandler("sh.keptn.event.evaluation.triggered", &TaskHandlerMock{}, TaskHandlerOptions{
+		SubscriptionFilter: models.EventSubscriptionFilter{Projects: []string{"prj"}, Stages: []string{"dev", "staging"}},
+	})(k)
+	WithTaskEventHandler("sh.keptn.event.release.*", &TaskHandlerMock{}, TaskHandlerOptions{})(k)
+	WithEventObserver("sh.keptn.event.test.finished", &recordingObserver{})(k)
+	return k
+}
+
+func Test_Subscriptions(t *testing.T) {
+	evaluation := models.EventSubscription{
+		Event:  "sh.keptn.event.evaluation.triggered",
+		Filter: models.EventSubscriptionFilter{Projects: []string{"prj"}, Stages: []string{"dev", "staging"}},
+	}
+	tests := []struct {
+		name string
+		mode SubscriptionMode
+		want []models.EventSubscription
+	}{
+		{
+			name: "PUBSUB_TOPIC only per default",
+			mode: SubscriptionModePubSubTopic,
+			want: []models.EventSubscription{
+				{Event: "sh.keptn.event.deployment.triggered"},
+				{Event: "sh.keptn.event.test.triggered"},
+			},
+		},
+		{
+			name: "derived from registered task handlers and observers",
+			mode: SubscriptionModeRegistry,
+			want: []models.EventSubscription{
+				evaluation,
+				{Event: "sh.keptn.event.release.>"},
+				{Event: "sh.keptn.event.test.triggered"},
+				{Event: "sh.keptn.event.test.finished"},
+			},
+		},
+		{
+			name: "merged without duplicates",
+			mode: SubscriptionModeMerged,
+			want: []models.EventSubscription{
+				{Event: "sh.keptn.event.deployment.triggered"},
+				{Event: "sh.keptn.event.test.triggered"},
+				evaluation,
+				{Event: "sh.keptn.event.release.>"},
+				{Event: "sh.keptn.event.test.finished"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := newSubscriptionTestKeptn(tt.mode)
+			require.Equal(t, tt.want, k.RegistrationData().Subscriptions)
+		})
+	}
+}
+
+func Test_Subscriptions_WithContextTaskHandler_IncludeAbortSignals(t *testing.T) {
+	for _, mode := range []SubscriptionMode{SubscriptionModePubSubTopic, SubscriptionModeRegistry, SubscriptionModeMerged} {
+		k := newSubscriptionTestKeptn(mode)
+		WithContextTaskHandler("sh.keptn.event.deployment.triggered", &ContextTaskHandlerMock{})(k)
+
+		subscriptions := k.RegistrationData().Subscriptions
+
+		require.Contains(t, subscriptions, models.EventSubscription{Event: "sh.keptn.event.*.invalidated"})
+		require.Contains(t, subscriptions, models.EventSubscription{Event: "sh.keptn.event.*.*.finished"})
+	}
+}
+
+func Test_Subscriptions_WithPendingTasks_IncludeAbortSignals(t *testing.T) {
+	k := newSubscriptionTestKeptn(SubscriptionModePubSubTopic)
+	WithPendingTasks(NewInMemoryPendingTaskStore())(k)
+
+	require.Equal(t, []models.EventSubscription{
+		{Event: "sh.keptn.event.deployment.triggered"},
+		{Event: "sh.keptn.event.test.triggered"},
+		{Event: "sh.keptn.event.*.invalidated"},
+		{Event: "sh.keptn.event.*.*.finished"},
+	}, k.RegistrationData().Subscriptions)
+}
+
+func Test_WhenReceivingAnEventNotMatchingTheSubscriptionFilter_HandlerIsNotExecuted(t *testing.T) {
+	executed := []string{}
+	handler := &TaskHandlerMock{ExecuteFunc: func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		executed = append(executed, event.ID)
+		return FakeTaskData{}, nil
+	}}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetSubscriptionMode(SubscriptionModeRegistry)
+	fakeKeptn.AddTaskEventHandler("sh.keptn.event.test.triggered", handler, TaskHandlerOptions{
+		SubscriptionFilter: models.EventSubscriptionFilter{Projects: []string{"prj"}},
+	})
+
+	ignored := newTestEvent("sh.keptn.event.test.triggered")
+	ignored.Data = v0_2_0.EventData{Project: "other", Stage: "stg", Service: "svc"}
+	ignored.ID = "ignored"
+	handled := newTestEvent("sh.keptn.event.test.triggered")
+	handled.ID = "handled"
+	fakeKeptn.NewEvent(ignored)
+	fakeKeptn.NewEvent(handled)
+
+	require.Equal(t, []string{"handled"}, executed)
+	fakeKeptn.AssertNumberOfEventSent(t, 2)
+}
@@ -1,6 +1,8 @@
 package sdk
 
 import (
+	"context"
+	"sort"
 	"sync"
 )
 
@@ -11,6 +13,8 @@ type taskRegistry struct {
 
 type taskEntry struct {
 	taskHandler TaskHandler
+	// contextTaskHandler is set instead of taskHandler if a ContextTaskHandler has been registered
+	contextTaskHandler ContextTaskHandler
 	// eventFilters is a list of functions that are executed before a task is handled by the taskHandler. Only if all functions return 'true', the task will be handled
 	eventFilters []func(keptnHandle IKeptn, event KeptnEvent) bool
 	// taskHandlerOpts are the options for the handler
@@ -23,15 +27,48 @@ func newTaskMap() *taskRegistry {
 	}
 }
 
+// Contains returns the entry registered for the given event type. If no entry has been registered for the exact event type,
+// the entry of the most specific matching event type pattern, e.g. sh.keptn.event.*.triggered, is returned
 func (t *taskRegistry) Contains(name string) (*taskEntry, bool) {
 	t.RLock()
 	defer t.RUnlock()
-	if e, ok := t.entries[name]; ok {
-		return &e, true
-	} else if e, ok := t.entries["*"]; ok { // check if we have registered a wildcard handler
-		return &e, true
+	match, ok := t.match(name)
+	if !ok {
+		return nil, false
 	}
-	return nil, false
+	e := t.entries[match]
+	return &e, true
+}
+
+// Match returns the event type or event type pattern the entry returned by Contains has been registered with
+func (t *taskRegistry) Match(name string) (string, bool) {
+	t.RLock()
+	defer t.RUnlock()
+	return t.match(name)
+}
+
+func (t *taskRegistry) match(name string) (string, bool) {
+	if _, ok := t.entries[name]; ok {
+		return name, true
+	}
+	match := ""
+	for pattern := range t.entries {
+		if !isEventTypePattern(pattern) || !matchEventType(pattern, name) {
+			continue
+		}
+		if match == "" || isMoreSpecific(pattern, match) {
+			match = pattern
+		}
+	}
+	return match, match != ""
+}
+
+// ContainsExactly checks whether an entry has been registered for exactly the given event type, i.e. not via a pattern
+func (t *taskRegistry) ContainsExactly(name string) bool {
+	t.RLock()
+	defer t.RUnlock()
+	_, ok := t.entries[name]
+	return ok
 }
 
 func (t *taskRegistry) Add(name string, entry taskEntry) {
@@ -40,9 +77,44 @@ func (t *taskRegistry) Add(name string, entry taskEntry) {
 	t.entries[name] = entry
 }
 
+type registeredTask struct {
+	eventType string
+	entry     taskEntry
+}
+
+// List returns all registered entries, sorted by event type
+func (t *taskRegistry) List() []registeredTask {
+	t.RLock()
+	defer t.RUnlock()
+	tasks := make([]registeredTask, 0, len(t.entries))
+	for eventType, entry := range t.entries {
+		tasks = append(tasks, registeredTask{eventType: eventType, entry: entry})
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].eventType < tasks[j].eventType
+	})
+	return tasks
+}
+
 func (t *taskRegistry) Get(name string) *taskEntry {
 	t.RLock()
 	defer t.RUnlock()
 	entry := t.entries[name]
 	return &entry
 }
+
+// execute runs the registered handler of the entry and passes the given context to it in case it is a ContextTaskHandler
+func (e *taskEntry) execute(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+	if e.contextTaskHandler != nil {
+		return e.contextTaskHandler.Execute(ctx, keptnHandle, event)
+	}
+	return e.taskHandler.Execute(keptnHandle, event)
+}
+
+// decodeEventData validates the data of the given event in case the registered handler expects typed event data
+func (e *taskEntry) decodeEventData(event KeptnEvent) error {
+	if decoder, ok := e.contextTaskHandler.(eventDataDecoder); ok {
+		return decoder.decodeEventData(event)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// ErrTaskResultNotFound is returned if no .finished event of the requested task exists in the keptn context and stage of an event
+var ErrTaskResultNotFound = errors.New("task result not found")
+
+// GetTaskResult decodes the data of the .finished event of the given task that has been sent most recently in the keptn
+// context and stage of the given event into out, e.g. a *keptnv2.DeploymentFinishedEventData for the deployment task.
+// If the task has not been finished in the keptn context and stage, ErrTaskResultNotFound is returned
+func (k *Keptn) GetTaskResult(event KeptnEvent, task string, out interface{}) error {
+	if k.apiV2 == nil {
+		return errors.New("unable to get task result: no Keptn API available")
+	}
+	eventData := keptnv2.EventData{}
+	if err := keptnv2.EventDataAs(models.KeptnContextExtendedCE(event), &eventData); err != nil {
+		return fmt.Errorf("could not decode data of event %s: %w", event.ID, err)
+	}
+
+	ctx := context.Background()
+	if taskCtx, ok := k.runningTasks.Get(models.KeptnContextExtendedCE(event)); ok {
+		ctx = taskCtx
+	}
+	events, mErr := k.apiV2.Events().GetEvents(ctx, &apiv2.EventFilter{
+		Project:      eventData.Project,
+		Stage:        eventData.Stage,
+		EventType:    keptnv2.GetFinishedEventType(task),
+		KeptnContext: event.Shkeptncontext,
+	}, apiv2.EventsGetEventsOptions{})
+	if mErr != nil {
+		return fmt.Errorf("could not get %s events: %w", keptnv2.GetFinishedEventType(task), mErr.ToError())
+	}
+
+	var latest *models.KeptnContextExtendedCE
+	for _, e := range events {
+		if e == nil || e.Shkeptncontext != event.Shkeptncontext {
+			continue
+		}
+		if latest == nil || e.Time.After(latest.Time) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return fmt.Errorf("%w: task %s in stage %s of keptn context %s", ErrTaskResultNotFound, task, eventData.Stage, event.Shkeptncontext)
+	}
+	if err := keptnv2.EventDataAs(*latest, out); err != nil {
+		return fmt.Errorf("could not decode data of event %s: %w", latest.ID, err)
+	}
+	return nil
+}
+
+// TaskResult returns the data of the .finished event of the given task that has been sent most recently in the
+// keptn context and stage of the given event, decoded as T, e.g. keptnv2.TestFinishedEventData
+func TaskResult[T any](keptnHandle IKeptn, event KeptnEvent, task string) (*T, error) {
+	result := new(T)
+	if err := keptnHandle.GetTaskResult(event, task, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeploymentResult returns the data of the most recent deployment.finished event in the keptn context and stage of the given event
+func DeploymentResult(keptnHandle IKeptn, event KeptnEvent) (*keptnv2.DeploymentFinishedEventData, error) {
+	return TaskResult[keptnv2.DeploymentFinishedEventData](keptnHandle, event, keptnv2.DeploymentTaskName)
+}
+
+// EvaluationResult returns the data of the most recent evaluation.finished event in the keptn context and stage of the given event
+func EvaluationResult(keptnHandle IKeptn, event KeptnEvent) (*keptnv2.EvaluationFinishedEventData, error) {
+	return TaskResult[keptnv2.EvaluationFinishedEventData](keptnHandle, event, keptnv2.EvaluationTaskName)
+}
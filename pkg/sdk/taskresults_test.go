@@ -0,0 +1,103 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	apiv2 "github.com/keptn/go-utils/pkg/api/utils/v2"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+type taskResultsKeptnInterfaceV2 struct {
+	apiv2.KeptnInterface
+	events *fakeEventsAPI
+}
+
+func (t taskResultsKeptnInterfaceV2) Events() apiv2.EventsInterface {
+	return t.events
+}
+
+// fakeEventsAPI returns the stored events matching the event type of the filter
+type fakeEventsAPI struct {
+	events  []*models.KeptnContextExtendedCE
+	filters []apiv2.EventFilter
+}
+
+func (f *fakeEventsAPI) GetEvents(ctx context.Context, filter *apiv2.EventFilter, opts apiv2.EventsGetEventsOptions) ([]*models.KeptnContextExtendedCE, *models.Error) {
+	f.filters = append(f.filters, *filter)
+	matching := []*models.KeptnContextExtendedCE{}
+	for _, event := range f.events {
+		if *event.Type == filter.EventType {
+			matching = append(matching, event)
+		}
+	}
+	return matching, nil
+}
+
+func (f *fakeEventsAPI) GetEventsWithRetry(ctx context.Context, filter *apiv2.EventFilter, maxRetries int, retrySleepTime time.Duration, opts apiv2.EventsGetEventsWithRetryOptions) ([]*models.KeptnContextExtendedCE, error) {
+	events, err := f.GetEvents(ctx, filter, apiv2.EventsGetEventsOptions{})
+	if err != nil {
+		return nil, err.ToError()
+	}
+	return events, nil
+}
+
+func newDeploymentFinishedEvent(keptnContext string, uri string, t time.Time) *models.KeptnContextExtendedCE {
+	return &models.KeptnContextExtendedCE{
+		Data: v0_2_0.DeploymentFinishedEventData{
+			EventData:  v0_2_0.EventData{Project: "prj", Stage: "stg", Service: "svc", Result: v0_2_0.ResultPass},
+			Deployment: v0_2_0.DeploymentFinishedData{DeploymentURIsPublic: []string{uri}},
+		},
+		ID:             uri,
+		Shkeptncontext: keptnContext,
+		Source:         strutils.Stringp("helm-service"),
+		Time:           t,
+		Type:           strutils.Stringp("sh.keptn.event.deployment.finished"),
+	}
+}
+
+func Test_WhenHandlingTestTask_DeploymentResultOfSequenceIsAvailable(t *testing.T) {
+	now := time.Now()
+	events := &fakeEventsAPI{events: []*models.KeptnContextExtendedCE{
+		newDeploymentFinishedEvent("context", "http://first", now.Add(-2*time.Minute)),
+		newDeploymentFinishedEvent("context", "http://latest", now.Add(-time.Minute)),
+		newDeploymentFinishedEvent("other-context", "http://other", now),
+	}}
+	var deploymentURIs []string
+	var evaluationErr error
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		deployment, err := DeploymentResult(keptnHandle, event)
+		require.Nil(t, err)
+		deploymentURIs = deployment.Deployment.DeploymentURIsPublic
+		_, evaluationErr = EvaluationResult(keptnHandle, event)
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetAPIV2(taskResultsKeptnInterfaceV2{events: events})
+	fakeKeptn.AddTaskHandler("sh.keptn.event.test.triggered", taskHandler)
+
+	fakeKeptn.NewEvent(newTestEvent("sh.keptn.event.test.triggered"))
+
+	require.Equal(t, []string{"http://latest"}, deploymentURIs)
+	require.ErrorIs(t, evaluationErr, ErrTaskResultNotFound)
+	require.Equal(t, apiv2.EventFilter{Project: "prj", Stage: "stg", EventType: "sh.keptn.event.deployment.finished", KeptnContext: "context"}, events.filters[0])
+	require.Equal(t, "sh.keptn.event.evaluation.finished", events.filters[1].EventType)
+}
+
+func Test_TaskResult_DecodesIntoGivenType(t *testing.T) {
+	events := &fakeEventsAPI{events: []*models.KeptnContextExtendedCE{
+		newDeploymentFinishedEvent("context", "http://deployed", time.Now()),
+	}}
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetAPIV2(taskResultsKeptnInterfaceV2{events: events})
+
+	result, err := TaskResult[v0_2_0.EventData](fakeKeptn.Keptn, KeptnEvent(newTestEvent("sh.keptn.event.test.triggered")), v0_2_0.DeploymentTaskName)
+
+	require.Nil(t, err)
+	require.Equal(t, v0_2_0.ResultPass, result.Result)
+}
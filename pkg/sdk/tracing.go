@@ -0,0 +1,98 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/observability"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/go-utils/pkg/sdk/connector/controlplane"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/keptn/go-utils/pkg/sdk"
+
+// WithTracerProvider configures the sdk to create spans using the given trace provider.
+// Per default the global trace provider is used, which can e.g. be set up via observability.InitOTelTraceProvider
+func WithTracerProvider(tracerProvider trace.TracerProvider) KeptnOption {
+	return func(k *Keptn) {
+		k.tracerProvider = tracerProvider
+	}
+}
+
+func (k *Keptn) tracer() trace.Tracer {
+	if k.tracerProvider != nil {
+		return k.tracerProvider.Tracer(tracerName)
+	}
+	return otel.GetTracerProvider().Tracer(tracerName)
+}
+
+// startProcessSpan starts the span covering the processing of a received event.
+// The span continues the trace whose context is contained in the extensions of the event
+func (k *Keptn) startProcessSpan(ctx context.Context, event models.KeptnContextExtendedCE) (context.Context, trace.Span) {
+	ctx = observability.ExtractTraceContext(ctx, event)
+	return k.tracer().Start(ctx, *event.Type+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(eventAttributes(event)...),
+	)
+}
+
+// startExecuteSpan starts the span covering the execution of a task handler
+func (k *Keptn) startExecuteSpan(ctx context.Context, event KeptnEvent) (context.Context, trace.Span) {
+	return k.tracer().Start(ctx, *event.Type+" execute",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(eventAttributes(models.KeptnContextExtendedCE(event))...),
+	)
+}
+
+// endExecuteSpan ends the given span and records the given error, if any
+func endExecuteSpan(span trace.Span, err *Error) {
+	if err != nil {
+		if err.Err != nil {
+			span.RecordError(err.Err)
+		}
+		span.SetStatus(codes.Error, err.Message)
+	}
+	span.End()
+}
+
+func eventAttributes(event models.KeptnContextExtendedCE) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{
+		attribute.String("keptn.event.id", event.ID),
+		attribute.String("keptn.event.type", *event.Type),
+		attribute.String("keptn.event.shkeptncontext", event.Shkeptncontext),
+		attribute.String("keptn.event.triggeredid", event.Triggeredid),
+	}
+	eventData := keptnv2.EventData{}
+	if err := keptnv2.EventDataAs(event, &eventData); err == nil {
+		attributes = append(attributes,
+			attribute.String("keptn.event.project", eventData.Project),
+			attribute.String("keptn.event.stage", eventData.Stage),
+			attribute.String("keptn.event.service", eventData.Service),
+		)
+	}
+	return attributes
+}
+
+// tracingEventSender returns an EventSender that injects the trace context of the given context into every event before sending it
+func tracingEventSender(ctx context.Context, sender controlplane.EventSender) controlplane.EventSender {
+	return func(ce models.KeptnContextExtendedCE) error {
+		observability.InjectTraceContext(ctx, &ce)
+		return sender(ce)
+	}
+}
+
+// traceContext returns the context carrying the trace context for an event sent in response to the given parent event.
+// If the parent event is still being processed, the context of its processing is used. Otherwise, the trace
+// context contained in the extensions of the parent event is continued
+func (k *Keptn) traceContext(parentEvent models.KeptnContextExtendedCE) context.Context {
+	if k.runningTasks != nil {
+		if ctx, ok := k.runningTasks.Get(parentEvent); ok {
+			return ctx
+		}
+	}
+	return observability.ExtractTraceContext(context.Background(), parentEvent)
+}
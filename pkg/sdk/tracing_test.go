@@ -0,0 +1,90 @@
+package sdk
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+func newTracingFakeKeptn(handler TaskHandler) (*FakeKeptn, *tracetest.SpanRecorder) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	fakeKeptn := NewFakeKeptn("fake")
+	fakeKeptn.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	fakeKeptn.AddTaskHandler("sh.keptn.event.faketask.triggered", handler)
+	return fakeKeptn, spanRecorder
+}
+
+func newTracedTestEvent() models.KeptnContextExtendedCE {
+	event := newTestEvent("sh.keptn.event.faketask.triggered")
+	event.Extensions = map[string]interface{}{"traceparent": testTraceParent}
+	return event
+}
+
+func spanByName(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	require.FailNow(t, "span not found", name)
+	return nil
+}
+
+func Test_WhenReceivingAnEvent_TraceIsContinued(t *testing.T) {
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		require.NoError(t, keptnHandle.SendStatusChangedEvent(event, v0_2_0.EventData{Message: "step 1"}))
+		return FakeTaskData{}, nil
+	}
+	fakeKeptn, spanRecorder := newTracingFakeKeptn(taskHandler)
+	fakeKeptn.NewEvent(newTracedTestEvent())
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 2)
+	processSpan := spanByName(t, spans, "sh.keptn.event.faketask.triggered process")
+	executeSpan := spanByName(t, spans, "sh.keptn.event.faketask.triggered execute")
+	require.Equal(t, testTraceID, processSpan.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", processSpan.Parent().SpanID().String())
+	require.Equal(t, trace.SpanKindConsumer, processSpan.SpanKind())
+	require.Equal(t, processSpan.SpanContext().SpanID(), executeSpan.Parent().SpanID())
+	require.Equal(t, codes.Unset, executeSpan.Status().Code)
+
+	fakeKeptn.AssertNumberOfEventSent(t, 3)
+	for i, sentEvent := range fakeKeptn.SentEvents {
+		extensions, ok := sentEvent.Extensions.(map[string]interface{})
+		require.True(t, ok, "event %d has no extensions", i)
+		require.Equal(t, fmt.Sprintf("00-%s-%s-01", testTraceID, processSpan.SpanContext().SpanID()), extensions["traceparent"])
+	}
+}
+
+func Test_WhenReceivingAnEvent_TaskHandlerFails_ErrorIsRecordedOnSpan(t *testing.T) {
+	taskHandler := &TaskHandlerMock{}
+	taskHandler.ExecuteFunc = func(keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+		return nil, &Error{
+			StatusType: v0_2_0.StatusErrored,
+			ResultType: v0_2_0.ResultFailed,
+			Message:    "something went wrong",
+			Err:        fmt.Errorf("something went wrong"),
+		}
+	}
+	fakeKeptn, spanRecorder := newTracingFakeKeptn(taskHandler)
+	fakeKeptn.NewEvent(newTracedTestEvent())
+
+	executeSpan := spanByName(t, spanRecorder.Ended(), "sh.keptn.event.faketask.triggered execute")
+	require.Equal(t, testTraceID, executeSpan.SpanContext().TraceID().String())
+	require.Equal(t, codes.Error, executeSpan.Status().Code)
+	require.Equal(t, "something went wrong", executeSpan.Status().Description)
+	require.Len(t, executeSpan.Events(), 1)
+}
@@ -0,0 +1,105 @@
+package sdk
+
+import (
+	"context"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// TypedTaskHandler is a task handler which receives the data of the event already decoded into a value of type T,
+// e.g. keptnv2.DeploymentTriggeredEventData, and returns the data of the .finished event as a value of type R,
+// e.g. keptnv2.DeploymentFinishedEventData.
+//
+// Returning a nil result means that no .finished event is sent, just like for a TaskHandler.
+// To mark the task as pending, R either embeds a PendingMarker, or is PendingResult itself.
+// If the event data cannot be decoded into T, the handler is not executed and the error is reported
+// to Keptn the same way as for an event that cannot be decoded at all
+type TypedTaskHandler[T any, R any] interface {
+	Execute(ctx context.Context, keptnHandle IKeptn, event KeptnEvent, data T) (*R, *Error)
+}
+
+// TypedTaskHandlerFunc is an adapter to allow the use of ordinary functions as TypedTaskHandler
+type TypedTaskHandlerFunc[T any, R any] func(ctx context.Context, keptnHandle IKeptn, event KeptnEvent, data T) (*R, *Error)
+
+// Execute calls f(ctx, keptnHandle, event, data)
+func (f TypedTaskHandlerFunc[T, R]) Execute(ctx context.Context, keptnHandle IKeptn, event KeptnEvent, data T) (*R, *Error) {
+	return f(ctx, keptnHandle, event, data)
+}
+
+// WithTypedTaskHandler registers a TypedTaskHandler which is responsible for processing a .triggered event.
+// Note, that if you want to have more control on configuring the behavior of the task handler,
+// you can use WithTypedTaskEventHandler instead
+func WithTypedTaskHandler[T any, R any](eventType string, handler TypedTaskHandler[T, R], filters ...func(keptnHandle IKeptn, event KeptnEvent) bool) KeptnOption {
+	return WithTypedTaskEventHandler[T, R](eventType, handler, TaskHandlerOptions{
+		Filters:               filters,
+		SkipAutomaticResponse: false,
+	})
+}
+
+// WithTypedTaskEventHandler registers a TypedTaskHandler which is responsible for processing a received .triggered event
+func WithTypedTaskEventHandler[T any, R any](eventType string, handler TypedTaskHandler[T, R], options TaskHandlerOptions) KeptnOption {
+	return WithContextTaskEventHandler(eventType, &typedTaskHandlerAdapter[T, R]{handler: handler}, options)
+}
+
+// PendingMarker can be embedded into the result type of a TypedTaskHandler, so that the handler is able to mark its task
+// as pending instead of finishing it, the same way a TaskHandler returns a PendingResult
+type PendingMarker struct {
+	pending *PendingResult
+}
+
+// MarkPending marks the task as pending with the given PendingResult, e.g. created via Pending
+func (m *PendingMarker) MarkPending(result *PendingResult) {
+	m.pending = result
+}
+
+func (m *PendingMarker) pendingResult() *PendingResult {
+	return m.pending
+}
+
+// pendingResultOf returns the PendingResult of a result returned by a TypedTaskHandler, or nil if the task is not pending
+func pendingResultOf(result interface{}) *PendingResult {
+	switch r := result.(type) {
+	case *PendingResult:
+		return r
+	case interface{ pendingResult() *PendingResult }:
+		return r.pendingResult()
+	}
+	return nil
+}
+
+// eventDataDecoder is implemented by task handlers that need to decode the event data before being executed
+type eventDataDecoder interface {
+	decodeEventData(event KeptnEvent) error
+}
+
+// typedTaskHandlerAdapter adapts a TypedTaskHandler to the ContextTaskHandler interface
+type typedTaskHandlerAdapter[T any, R any] struct {
+	handler TypedTaskHandler[T, R]
+}
+
+func (a *typedTaskHandlerAdapter[T, R]) decodeEventData(event KeptnEvent) error {
+	_, err := a.decode(event)
+	return err
+}
+
+func (a *typedTaskHandlerAdapter[T, R]) decode(event KeptnEvent) (T, error) {
+	var data T
+	err := keptnv2.Decode(event.Data, &data)
+	return data, err
+}
+
+func (a *typedTaskHandlerAdapter[T, R]) Execute(ctx context.Context, keptnHandle IKeptn, event KeptnEvent) (interface{}, *Error) {
+	data, err := a.decode(event)
+	if err != nil {
+		return nil, &Error{StatusType: keptnv2.StatusErrored, ResultType: keptnv2.ResultFailed, Message: err.Error(), Err: err}
+	}
+	result, handlerErr := a.handler.Execute(ctx, keptnHandle, event, data)
+	if result == nil {
+		// avoid returning a non-nil interface holding a nil pointer
+		return nil, handlerErr
+	}
+	if pending := pendingResultOf(result); pending != nil {
+		return pending, handlerErr
+	}
+	return *result, handlerErr
+}
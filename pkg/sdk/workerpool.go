@@ -0,0 +1,234 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWorkerPoolSaturated is returned if an event cannot be processed because all workers are busy
+// and the queue of the worker pool is full
+var ErrWorkerPoolSaturated = errors.New("worker pool saturated")
+
+// SaturationPolicy determines what happens with a received event if the worker pool is saturated
+type SaturationPolicy int
+
+const (
+	// SaturationPolicyBlock blocks the receiving of further events from the control plane until
+	// there is space in the queue of the worker pool again
+	SaturationPolicyBlock SaturationPolicy = iota
+	// SaturationPolicyReject rejects the event by responding with an errored .finished event
+	SaturationPolicyReject
+)
+
+// WorkerPoolOptions are the options for limiting the number of concurrently processed events
+type WorkerPoolOptions struct {
+	// MaxWorkers is the maximum number of events processed concurrently. A value <= 0 means no limit
+	MaxWorkers int
+	// QueueSize is the maximum number of events waiting for a free worker
+	QueueSize int
+	// SaturationPolicy determines what happens with an event if all workers are busy and the queue is full
+	SaturationPolicy SaturationPolicy
+}
+
+// WorkerPoolStatus describes the current utilization of a worker pool
+type WorkerPoolStatus struct {
+	// Running is the number of events currently being processed
+	Running int `json:"running"`
+	// Queued is the number of events waiting for a free worker
+	Queued int `json:"queued"`
+	// MaxWorkers is the configured maximum number of events processed concurrently
+	MaxWorkers int `json:"maxWorkers"`
+	// QueueSize is the configured maximum number of queued events
+	QueueSize int `json:"queueSize"`
+}
+
+// WorkerPoolsStatus describes the current utilization of all configured worker pools
+type WorkerPoolsStatus struct {
+	// Global is the status of the worker pool configured via WithWorkerPool, or nil if there is none
+	Global *WorkerPoolStatus `json:"global,omitempty"`
+	// EventTypes contains the status of the worker pools configured via WithEventTypeWorkerPool
+	EventTypes map[string]WorkerPoolStatus `json:"eventTypes,omitempty"`
+}
+
+// WithWorkerPool limits the number of events that are processed concurrently by all task handlers.
+// Per default, every received event is processed immediately in its own goroutine
+func WithWorkerPool(options WorkerPoolOptions) KeptnOption {
+	return func(k *Keptn) {
+		k.workerPools.global = newWorkerPool(options)
+	}
+}
+
+// WithEventTypeWorkerPool limits the number of events of the given type that are processed concurrently.
+// The event type can also be the pattern a task handler has been registered with, e.g. sh.keptn.event.*.triggered,
+// limiting all events handled by that handler for which there is no pool of their exact type.
+// The limit applies in addition to the one configured via WithWorkerPool
+func WithEventTypeWorkerPool(eventType string, options WorkerPoolOptions) KeptnOption {
+	return func(k *Keptn) {
+		k.workerPools.add(eventType, newWorkerPool(options))
+	}
+}
+
+type workerPool struct {
+	options WorkerPoolOptions
+	// capacity holds a token for every event that is either running or queued
+	capacity chan struct{}
+	// workers holds a token for every event that is running
+	workers chan struct{}
+}
+
+func newWorkerPool(options WorkerPoolOptions) *workerPool {
+	if options.MaxWorkers <= 0 {
+		return nil
+	}
+	if options.QueueSize < 0 {
+		options.QueueSize = 0
+	}
+	return &workerPool{
+		options:  options,
+		capacity: make(chan struct{}, options.MaxWorkers+options.QueueSize),
+		workers:  make(chan struct{}, options.MaxWorkers),
+	}
+}
+
+// enqueue reserves a place for an event in the pool. Depending on the SaturationPolicy it
+// either blocks until there is space available or returns ErrWorkerPoolSaturated
+func (p *workerPool) enqueue() error {
+	if p.options.SaturationPolicy == SaturationPolicyReject {
+		select {
+		case p.capacity <- struct{}{}:
+			return nil
+		default:
+			return ErrWorkerPoolSaturated
+		}
+	}
+	p.capacity <- struct{}{}
+	return nil
+}
+
+// dequeue releases a place reserved via enqueue without running the event
+func (p *workerPool) dequeue() {
+	<-p.capacity
+}
+
+// acquire blocks until a worker is available for an enqueued event or the given context is cancelled
+func (p *workerPool) acquire(ctx context.Context) error {
+	select {
+	case p.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// release frees the worker and the place of an event after it has been processed
+func (p *workerPool) release() {
+	<-p.workers
+	<-p.capacity
+}
+
+func (p *workerPool) status() WorkerPoolStatus {
+	running := len(p.workers)
+	return WorkerPoolStatus{
+		Running:    running,
+		Queued:     len(p.capacity) - running,
+		MaxWorkers: p.options.MaxWorkers,
+		QueueSize:  p.options.QueueSize,
+	}
+}
+
+type workerPools struct {
+	sync.RWMutex
+	global     *workerPool
+	eventTypes map[string]*workerPool
+}
+
+func newWorkerPools() *workerPools {
+	return &workerPools{
+		eventTypes: make(map[string]*workerPool),
+	}
+}
+
+func (w *workerPools) add(eventType string, pool *workerPool) {
+	w.Lock()
+	defer w.Unlock()
+	if pool == nil {
+		delete(w.eventTypes, eventType)
+		return
+	}
+	w.eventTypes[eventType] = pool
+}
+
+// forEventType returns the pools that are responsible for events of the given type, handled by the task handler
+// registered for the given event type or event type pattern. The pool of the exact event type, or otherwise the one
+// of the task handler's registration comes first, followed by the global pool
+func (w *workerPools) forEventType(eventType string, registeredEventType string) poolChain {
+	w.RLock()
+	defer w.RUnlock()
+	chain := poolChain{}
+	if pool, ok := w.eventTypes[eventType]; ok {
+		chain = append(chain, pool)
+	} else if pool, ok := w.eventTypes[registeredEventType]; ok {
+		chain = append(chain, pool)
+	}
+	if w.global != nil {
+		chain = append(chain, w.global)
+	}
+	return chain
+}
+
+func (w *workerPools) status() WorkerPoolsStatus {
+	w.RLock()
+	defer w.RUnlock()
+	status := WorkerPoolsStatus{}
+	if w.global != nil {
+		globalStatus := w.global.status()
+		status.Global = &globalStatus
+	}
+	if len(w.eventTypes) > 0 {
+		status.EventTypes = make(map[string]WorkerPoolStatus, len(w.eventTypes))
+		for eventType, pool := range w.eventTypes {
+			status.EventTypes[eventType] = pool.status()
+		}
+	}
+	return status
+}
+
+// poolChain is a list of worker pools an event needs to pass. The pools are always
+// entered in the same order to avoid deadlocks
+type poolChain []*workerPool
+
+func (c poolChain) enqueue() error {
+	for i, pool := range c {
+		if err := pool.enqueue(); err != nil {
+			for _, enqueued := range c[:i] {
+				enqueued.dequeue()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// acquire blocks until a worker is available in every pool. If the given context is cancelled while waiting,
+// the acquired workers as well as the places reserved via enqueue are released and the cause is returned
+func (c poolChain) acquire(ctx context.Context) error {
+	for i, pool := range c {
+		if err := pool.acquire(ctx); err != nil {
+			for _, acquired := range c[:i] {
+				<-acquired.workers
+			}
+			for _, enqueued := range c {
+				enqueued.dequeue()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c poolChain) release() {
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i].release()
+	}
+}